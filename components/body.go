@@ -0,0 +1,166 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"me.kryptk.overcommit/utils"
+)
+
+type bodyGeneratedMsg struct {
+	text     string
+	backend  string
+	cached   bool
+	redacted int
+	err      error
+}
+
+type BodyView struct {
+	input      textarea.Model
+	spinner    spinner.Model
+	lint       utils.Lint
+	llmCfg     utils.LLMConfig
+	llmClient  utils.LLMClient
+	generating bool
+	err        string
+	notes      []string
+}
+
+func NewBodyView(lint utils.Lint, llmCfg utils.LLMConfig) BodyView {
+	ta := textarea.New()
+	ta.Placeholder = "explain why"
+	ta.ShowLineNumbers = false
+	ta.SetWidth(lint.BodyLineWidth + 2)
+	ta.SetHeight(8)
+	ta.Focus()
+
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Accent))
+
+	return BodyView{
+		input:     ta,
+		spinner:   sp,
+		lint:      lint,
+		llmCfg:    llmCfg,
+		llmClient: utils.NewLLMClient(llmCfg),
+	}
+}
+
+func (b BodyView) Value() string {
+	return strings.TrimSpace(b.input.Value())
+}
+
+// Generate starts generating a body for the subject entered in v.
+func (b *BodyView) Generate(v PageView) tea.Cmd {
+	b.generating = true
+	b.err = ""
+
+	generate := func() tea.Msg {
+		prompt, redacted, err := utils.PrepareBodyPrompt(b.llmCfg, v.selected.Prefix, v.scope, v.subject)
+		if err != nil {
+			return bodyGeneratedMsg{redacted: redacted, err: err}
+		}
+
+		resp, err := utils.GenerateBody(b.llmClient, prompt, b.lint)
+		return bodyGeneratedMsg{text: resp.Text, backend: resp.Backend, cached: resp.Cached, redacted: redacted, err: err}
+	}
+	return tea.Batch(b.spinner.Tick, generate)
+}
+
+func (b *BodyView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case bodyGeneratedMsg:
+		b.generating = false
+		b.notes = nil
+		if msg.backend != "" {
+			b.notes = append(b.notes, "via "+msg.backend)
+		}
+		if msg.cached {
+			b.notes = append(b.notes, "cached generation")
+		}
+		if msg.redacted > 0 {
+			b.notes = append(b.notes, fmt.Sprintf("%d secrets redacted before sending", msg.redacted))
+		}
+		if msg.err != nil {
+			b.err = msg.err.Error()
+		} else {
+			b.input.SetValue(msg.text)
+			b.err = ""
+		}
+		return v, nil
+
+	case spinner.TickMsg:
+		if b.generating {
+			b.spinner, cmd = b.spinner.Update(msg)
+			return v, cmd
+		}
+
+	case tea.KeyMsg:
+		if b.generating {
+			return v, nil
+		}
+		if msg.Type == tea.KeyRunes && !msg.Alt {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, v.Keys.GenerateBody):
+			return v, b.Generate(v)
+		case key.Matches(msg, v.Keys.Back):
+			return v.back(), nil
+		case key.Matches(msg, v.Keys.CommitBody, v.Keys.Trailers):
+			if violations := utils.LintBody(b.input.Value(), b.lint); len(violations) > 0 {
+				b.err = strings.Join(violations, ", ")
+				return v, nil
+			}
+			if key.Matches(msg, v.Keys.Trailers) && v.Trailers != nil {
+				v.Trailers.load()
+				return v.navigate(TRAILERS), nil
+			}
+			return v.finish()
+		}
+	}
+
+	b.input, cmd = b.input.Update(msg)
+	return v, cmd
+}
+
+// SetSize fits the textarea into width x height, never wider than the
+// body line width so the text wraps where it will be wrapped in git.
+func (b *BodyView) SetSize(width, height int) {
+	b.input.SetWidth(min(b.lint.BodyLineWidth+2, width))
+	b.input.SetHeight(height)
+}
+
+func (b BodyView) View(v PageView) string {
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	errStyle := term.String().Bold().Foreground(ERROR).Styled
+
+	view := fmt.Sprintf("%s : %s\n", style("[Subject]"), v.header())
+	if b.generating {
+		view += fmt.Sprintf("%s : %s generating...", style("[Body]"), b.spinner.View())
+	} else {
+		view += fmt.Sprintf("%s :\n%s", style("[Body]"), b.input.View())
+	}
+
+	if len(b.notes) > 0 {
+		view += "\n" + term.String(strings.Join(b.notes, " · ")).Faint().String()
+	}
+	if b.err != "" {
+		view += "\n" + errStyle(b.err)
+	}
+
+	if !v.compact() {
+		view += "\n\n" + v.preview(v.subject, b.input.Value(), b.lint)
+	}
+
+	return view
+}
@@ -0,0 +1,69 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+type crumb struct {
+	page  Page
+	label string
+	value string
+	done  bool
+}
+
+// breadcrumb renders the steps of the commit flow, marking completed steps
+// and highlighting the current one.
+func (p PageView) breadcrumb() string {
+	scope := p.scope
+	if scope == "" && p.Page > SCOPE {
+		scope = "none"
+	}
+
+	var crumbs []crumb
+	if p.Files != nil {
+		n := p.Files.Staged()
+		crumbs = append(crumbs, crumb{FILES, "files", fmt.Sprintf("%d staged", n), n > 0})
+	}
+	crumbs = append(crumbs, []crumb{
+		{SELECTION, "type", p.selected.Prefix, p.selected.Prefix != ""},
+		{SCOPE, "scope", scope, scope != ""},
+		{MSG, "message", "", p.subject != ""},
+		{BODY, "body", "", p.Body != nil && p.Body.Value() != ""},
+	}...)
+	if p.Trailers != nil {
+		n := len(p.Trailers.Trailers())
+		crumbs = append(crumbs, crumb{TRAILERS, "trailers", fmt.Sprint(n), n > 0})
+	}
+
+	parts := make([]string, len(crumbs))
+	for i, c := range crumbs {
+		txt := c.label
+		if c.value != "" {
+			txt += " " + c.value
+		}
+		if c.done {
+			txt = "✓ " + txt
+		}
+
+		switch {
+		case c.page == p.Page:
+			parts[i] = term.String(txt).Bold().Foreground(ACCENT).String()
+		case c.done:
+			parts[i] = txt
+		default:
+			parts[i] = term.String(txt).Faint().String()
+		}
+	}
+
+	crumb := strings.Join(parts, term.String(" › ").Faint().String())
+	if opts := p.Options.Active(); len(opts) > 0 {
+		crumb += term.String("  [" + strings.Join(opts, " · ") + "]").Faint().String()
+	}
+	if p.width > 0 {
+		crumb = ansi.Truncate(crumb, p.width, "…")
+	}
+	return crumb
+}
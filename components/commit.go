@@ -2,61 +2,70 @@ package components
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/termenv"
+	"github.com/charmbracelet/x/ansi"
 	"me.kryptk.overcommit/utils"
 )
 
 type generatedMsg struct {
-	text string
-	err  error
+	text     string
+	backend  string
+	cached   bool
+	redacted int
+	err      error
 }
 
 type CommitView struct {
 	msgInput   textinput.Model
 	spinner    spinner.Model
 	maxLength  int
+	lint       utils.Lint
 	err        string
+	llmCfg     utils.LLMConfig
 	llmClient  utils.LLMClient
 	generating bool
+	backend    string
+	cached     bool
+	redacted   int
+	history    history
 }
 
-func NewCommitView(maxLength int, llmCfg utils.LLMConfig) CommitView {
+func NewCommitView(lint utils.Lint, llmCfg utils.LLMConfig) CommitView {
 	ti := textinput.New()
 	ti.Prompt = ""
-	ti.Placeholder = "describe your change (ctrl+g to generate)"
+	ti.Placeholder = "describe your change"
 	ti.Focus()
 
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
-	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#8AA8F9"))
+	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Accent))
 
 	return CommitView{
 		msgInput:  ti,
 		spinner:   sp,
-		maxLength: maxLength,
+		maxLength: lint.MaxSubjectLength,
+		lint:      lint,
+		llmCfg:    llmCfg,
 		llmClient: utils.NewLLMClient(llmCfg),
+		history:   history{index: -1},
 	}
 }
 
 func (c *CommitView) generate(v PageView) tea.Cmd {
 	return func() tea.Msg {
-		diff, err := utils.GetStagedDiff()
+		prompt, redacted, err := utils.PreparePrompt(c.llmCfg, v.selected.Prefix, v.scope)
 		if err != nil {
-			return generatedMsg{err: err}
-		}
-		if diff == "" {
-			return generatedMsg{err: fmt.Errorf("no staged changes")}
+			return generatedMsg{redacted: redacted, err: err}
 		}
 
-		prompt := utils.BuildPrompt(v.selected.Prefix, v.scope, diff)
-		text, err := c.llmClient.Generate(prompt)
-		return generatedMsg{text: text, err: err}
+		resp, err := utils.GenerateSubject(c.llmClient, prompt, c.lint, c.llmCfg.Retries)
+		return generatedMsg{text: resp.Text, backend: resp.Backend, cached: resp.Cached, redacted: redacted, err: err}
 	}
 }
 
@@ -66,6 +75,9 @@ func (c *CommitView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case generatedMsg:
 		c.generating = false
+		c.redacted = msg.redacted
+		c.cached = msg.cached
+		c.backend = msg.backend
 		if msg.err != nil {
 			c.err = msg.err.Error()
 		} else {
@@ -84,31 +96,55 @@ func (c *CommitView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd) {
 		if c.generating {
 			return v, nil
 		}
+		if c.history.searching {
+			return c.updateSearch(msg, v)
+		}
+		// plain characters are always typed, even when a preset binds them
+		if msg.Type == tea.KeyRunes && !msg.Alt {
+			break
+		}
 
-		switch msg.String() {
-		case "ctrl+g":
+		switch {
+		case key.Matches(msg, v.Keys.Back):
+			return v.back(), nil
+		case key.Matches(msg, v.Keys.HistoryPrev, v.Keys.HistoryNext):
+			c.history.load(v)
+			delta := 1
+			if key.Matches(msg, v.Keys.HistoryNext) {
+				delta = -1
+			}
+			if subject, ok := c.history.recall(delta, c.msgInput.Value()); ok {
+				c.msgInput.SetValue(subject)
+				c.msgInput.CursorEnd()
+			}
+			return v, nil
+		case key.Matches(msg, v.Keys.HistorySearch):
+			c.startSearch(v)
+			return v, nil
+		case key.Matches(msg, v.Keys.Generate):
 			c.generating = true
 			c.err = ""
 			return v, tea.Batch(c.spinner.Tick, c.generate(v))
-		case "enter":
+		case key.Matches(msg, v.Keys.Commit, v.Keys.Skip, v.Keys.GenerateBody, v.Keys.Trailers):
 			val := c.msgInput.Value()
-			if len(val) == 0 {
-				c.err = "message required"
-				return v, nil
-			}
-			if len(val) > c.maxLength {
-				c.err = fmt.Sprintf("exceeds %d chars", c.maxLength)
+			if violations := utils.LintSubject(v.ticketed(val), c.lint); len(violations) > 0 {
+				c.err = strings.Join(violations, ", ")
 				return v, nil
 			}
 			c.err = ""
-
-			commitMsg := utils.BuildCommitMessage(v.Template, v.selected.Prefix, v.scope, val)
-			v.FinalMessage = commitMsg
-
-			if len(os.Args) > 1 {
-				_ = utils.ReplaceHeaderFromCommit(commitMsg, os.Args[1])
+			v.subject = val
+
+			switch {
+			case key.Matches(msg, v.Keys.Skip):
+				return v.navigate(BODY), nil
+			case key.Matches(msg, v.Keys.GenerateBody):
+				v = v.navigate(BODY)
+				return v, v.Body.Generate(v)
+			case key.Matches(msg, v.Keys.Trailers) && v.Trailers != nil:
+				v.Trailers.load()
+				return v.navigate(TRAILERS), nil
 			}
-			return v, tea.Quit
+			return v.finish()
 		}
 	}
 
@@ -116,9 +152,14 @@ func (c *CommitView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd) {
 	return v, cmd
 }
 
+// SetWidth fits the input behind the label and counter.
+func (c *CommitView) SetWidth(width int) {
+	c.msgInput.Width = max(width-len("[Message] [00/00] : ")-1, 10)
+}
+
 func (c CommitView) View(v PageView) string {
-	style := termenv.String().Bold().Foreground(ACCENT).Styled
-	errStyle := termenv.String().Bold().Foreground(term.Color("#FF5555")).Styled
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	errStyle := term.String().Bold().Foreground(ERROR).Styled
 
 	currentLen := len(c.msgInput.Value())
 	counter := fmt.Sprintf("[%d/%d]", currentLen, c.maxLength)
@@ -126,9 +167,17 @@ func (c CommitView) View(v PageView) string {
 		counter = errStyle(counter)
 	}
 
-	view := fmt.Sprintf("%s : %s - %s\n", style("[Commit Type]"), v.selected.Prefix, v.selected.Description)
-	if v.scope != "" {
-		view += fmt.Sprintf("%s : %s\n", style("[Scope]"), v.scope)
+	// the breadcrumb already shows type and scope
+	view := ""
+	if !v.compact() {
+		view = fmt.Sprintf("%s : %s - %s", style("[Commit Type]"), v.selected.Prefix, v.selected.Description)
+		if v.width > 0 {
+			view = ansi.Truncate(view, v.width, "…")
+		}
+		view += "\n"
+		if v.scope != "" {
+			view += fmt.Sprintf("%s : %s\n", style("[Scope]"), v.scope)
+		}
 	}
 
 	if c.generating {
@@ -136,10 +185,38 @@ func (c CommitView) View(v PageView) string {
 	} else {
 		view += fmt.Sprintf("%s %s : %s", style("[Message]"), counter, c.msgInput.View())
 	}
+	if c.history.searching {
+		view += "\n" + c.searchView()
+	}
+
+	var notes []string
+	if c.backend != "" {
+		notes = append(notes, "via "+c.backend)
+	}
+	if c.cached {
+		notes = append(notes, "cached generation")
+	}
+	if h := c.history; h.index >= 0 {
+		notes = append(notes, fmt.Sprintf("history %d/%d", h.index+1, len(h.subjects)))
+	}
+	if c.redacted > 0 {
+		notes = append(notes, fmt.Sprintf("%d secrets redacted before sending", c.redacted))
+	}
+	if len(notes) > 0 {
+		view += "\n" + term.String(strings.Join(notes, " · ")).Faint().String()
+	}
 
 	if c.err != "" {
 		view += "\n" + errStyle(c.err)
 	}
 
+	body := ""
+	if v.Body != nil {
+		body = v.Body.Value()
+	}
+	if !v.compact() {
+		view += "\n\n" + v.preview(c.msgInput.Value(), body, c.lint)
+	}
+
 	return view
 }
@@ -0,0 +1,160 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"me.kryptk.overcommit/utils"
+)
+
+// Draft is the message as entered so far, including a subject that has
+// not been confirmed yet.
+func (p PageView) Draft() utils.Draft {
+	d := utils.Draft{Branch: p.Branch, Gitmoji: p.gitmoji, Type: p.selected.Prefix, Scope: p.scope, Breaking: p.breaking, Subject: p.subject}
+	if p.Committer != nil {
+		d.Subject = p.Committer.msgInput.Value()
+	}
+	if p.Body != nil {
+		d.Body = p.Body.Value()
+	}
+	return d
+}
+
+// draftDelay is how long typing has to pause before the draft is saved.
+const draftDelay = 500 * time.Millisecond
+
+type saveDraftMsg struct{ seq int }
+
+// persist schedules saving the draft of p.Branch whenever it changed,
+// drafts are off without a branch. Saves are debounced, only the draft
+// still current after draftDelay is written. Once the message is final
+// the caller decides whether to keep it, depending on how git commit went.
+func (p *PageView) persist() tea.Cmd {
+	if p.Branch == "" || p.Page == RESUME || p.FinalMessage != "" {
+		return nil
+	}
+	d := p.Draft()
+	if d.Same(p.saved) || (p.saveSeq > 0 && d.Same(p.pending)) {
+		return nil
+	}
+	p.saveSeq++
+	p.pending = d
+	seq := p.saveSeq
+	return tea.Tick(draftDelay, func(time.Time) tea.Msg { return saveDraftMsg{seq} })
+}
+
+// FlushDraft writes a draft whose save was still pending, for when the
+// program quit before the delay was up.
+func (p *PageView) FlushDraft() {
+	if p.Branch == "" || p.Page == RESUME || p.FinalMessage != "" {
+		return
+	}
+	if d := p.Draft(); !d.Same(p.saved) && utils.SaveDraft(d) == nil {
+		p.saved = d
+	}
+}
+
+// Resume asks whether to continue with d before showing the current page.
+func (p PageView) Resume(d utils.Draft) PageView {
+	p.draft = &d
+	p.saved = d
+	p.resumeFrom = p.Page
+	p.Page = RESUME
+	return p
+}
+
+func (p PageView) updateResume(m tea.Msg) (PageView, tea.Cmd) {
+	msg, ok := m.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch {
+	case key.Matches(msg, p.Keys.Yes):
+		return p.restore(*p.draft), nil
+	case key.Matches(msg, p.Keys.No):
+		_ = utils.ClearDraft(p.Branch)
+		p.draft = nil
+		p.saved = utils.Draft{}
+		p.Page = p.resumeFrom
+	}
+	return p, nil
+}
+
+// Prefill starts on the message page with every field taken from d, as
+// when amending a commit.
+func (p PageView) Prefill(d utils.Draft) PageView {
+	p.resumeFrom = p.Page
+	return p.restore(d)
+}
+
+// restore fills every page from d and continues on the message page,
+// with type and scope in the history so they can still be changed.
+func (p PageView) restore(d utils.Draft) PageView {
+	p.draft = nil
+	p.Page = p.resumeFrom
+
+	if p.Committer != nil {
+		p.Committer.msgInput.SetValue(d.Subject)
+	}
+	if p.Body != nil {
+		p.Body.input.SetValue(d.Body)
+	}
+	if p.Selector == nil {
+		return p
+	}
+	k, ok := p.Selector.find(d.Type)
+	if !ok {
+		return p
+	}
+
+	p.selected, p.gitmoji = k, d.Gitmoji
+	p.Selector.Select(k.Prefix)
+	p.scope, p.breaking = d.Scope, d.Breaking
+	if d.Scope != "" && p.ScopeSelector != nil {
+		p.ScopeSelector.Select(d.Scope)
+	}
+	if p.Page != SELECTION {
+		p = p.navigate(SELECTION)
+	}
+	return p.navigate(SCOPE).navigate(MSG)
+}
+
+func (p PageView) resumeView() string {
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	faint := func(s string) string { return term.String(s).Faint().String() }
+	d := *p.draft
+
+	k := utils.Key{Prefix: d.Type}
+	if p.Selector != nil {
+		if found, ok := p.Selector.find(d.Type); ok {
+			k = found
+		}
+	}
+	msg := p.buildHeader(k, d.Scope, d.Subject, d.Gitmoji, d.Breaking)
+	if d.Body != "" {
+		msg += "\n\n" + d.Body
+	}
+
+	view := fmt.Sprintf("%s %s\n", style("[Draft]"), faint(fmt.Sprintf("on %s · saved %s", d.Branch, ago(d.Updated))))
+	for _, line := range strings.Split(msg, "\n") {
+		view += faint("│ ") + line + "\n"
+	}
+	return view + "Resume this draft?"
+}
+
+func ago(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -0,0 +1,327 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"me.kryptk.overcommit/utils"
+)
+
+// FilesView lists the working tree changes and lets the user stage or
+// unstage whole files or single hunks before committing.
+type FilesView struct {
+	entries    []utils.StatusEntry
+	cursor     int
+	diff       utils.FileDiff
+	syntax     [][]string
+	showStaged bool
+	hunk       int
+	hunkFocus  bool
+	hunkLines  []int
+	viewport   viewport.Model
+	err        string
+	width      int
+	height     int
+}
+
+func NewFilesView() FilesView {
+	f := FilesView{viewport: viewport.New(80, 12)}
+	f.Refresh()
+	return f
+}
+
+// Refresh reloads git status and the diff of the file under the cursor.
+func (f *FilesView) Refresh() {
+	entries, err := utils.GetStatus()
+	if err != nil {
+		f.err = err.Error()
+		return
+	}
+	f.entries = entries
+	if f.cursor >= len(entries) {
+		f.cursor = max(len(entries)-1, 0)
+	}
+	f.layout()
+	f.loadDiff()
+}
+
+func (f *FilesView) SetSize(width, height int) {
+	f.width, f.height = width, height
+	f.layout()
+	f.render()
+}
+
+// listRows is how many files are shown at once, the diff gets the rest.
+func (f FilesView) listRows() int {
+	if f.height == 0 {
+		return len(f.entries)
+	}
+	return max(min(len(f.entries), f.height/3), 1)
+}
+
+func (f *FilesView) layout() {
+	if f.height == 0 {
+		return
+	}
+	f.viewport.Width = f.width
+	// titles of both panes and the blank line between them
+	f.viewport.Height = max(f.height-f.listRows()-3, 3)
+}
+
+// Staged is the number of files with staged changes.
+func (f FilesView) Staged() int {
+	n := 0
+	for _, e := range f.entries {
+		if e.Staged() {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *FilesView) loadDiff() {
+	f.diff = utils.FileDiff{}
+	f.syntax = nil
+	f.hunk = 0
+	if len(f.entries) == 0 {
+		f.hunkFocus = false
+		f.viewport.SetContent("")
+		return
+	}
+
+	e := f.entries[f.cursor]
+	if !e.Staged() {
+		f.showStaged = false
+	} else if !e.Unstaged() {
+		f.showStaged = true
+	}
+
+	diff, err := utils.GetFileDiff(e, f.showStaged)
+	if err != nil {
+		f.err = err.Error()
+	}
+	f.diff = diff
+	f.syntax = highlightHunks(diff)
+	if len(diff.Hunks) == 0 {
+		f.hunkFocus = false
+	}
+	f.render()
+	f.viewport.GotoTop()
+}
+
+// render colours the diff, highlighted by language where possible, and
+// marks the selected hunk.
+func (f *FilesView) render() {
+	add := term.String().Foreground(SUCCESS).Styled
+	del := term.String().Foreground(ERROR).Styled
+
+	var b strings.Builder
+	f.hunkLines = f.hunkLines[:0]
+	line := 0
+	for _, h := range f.diff.Header {
+		b.WriteString(term.String(h).Faint().String() + "\n")
+		line++
+	}
+	if f.diff.Binary {
+		b.WriteString("binary file\n")
+	}
+
+	for i, h := range f.diff.Hunks {
+		f.hunkLines = append(f.hunkLines, line)
+		if f.hunkFocus && i == f.hunk {
+			b.WriteString(term.String("▶ "+h.Header).Bold().Foreground(ACCENT).String() + "\n")
+		} else {
+			b.WriteString(term.String("  "+h.Header).Foreground(ACCENT).String() + "\n")
+		}
+		line++
+
+		for j, l := range h.Lines {
+			if f.syntax != nil && f.syntax[i] != nil && l != "" && l[0] != '\\' {
+				l = syntaxLine(l[:1], f.syntax[i][j], add, del)
+			} else if strings.HasPrefix(l, "+") {
+				l = add(l)
+			} else if strings.HasPrefix(l, "-") {
+				l = del(l)
+			}
+			b.WriteString("  " + l + "\n")
+			line++
+		}
+	}
+	f.viewport.SetContent(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// syntaxLine puts the coloured +/- marker in front of a highlighted line.
+func syntaxLine(marker, code string, add, del func(string) string) string {
+	switch marker {
+	case "+":
+		return add(marker) + code
+	case "-":
+		return del(marker) + code
+	}
+	return marker + code
+}
+
+func (f *FilesView) Update(m tea.Msg, v PageView) (PageView, tea.Cmd) {
+	var cmd tea.Cmd
+
+	msg, ok := m.(tea.KeyMsg)
+	if !ok {
+		return v, nil
+	}
+	f.err = ""
+
+	keys := v.Keys
+	if f.hunkFocus {
+		switch {
+		case key.Matches(msg, keys.Up):
+			if f.hunk > 0 {
+				f.hunk--
+				f.render()
+				f.viewport.SetYOffset(f.hunkLines[f.hunk])
+			}
+		case key.Matches(msg, keys.Down):
+			if f.hunk < len(f.diff.Hunks)-1 {
+				f.hunk++
+				f.render()
+				f.viewport.SetYOffset(f.hunkLines[f.hunk])
+			}
+		case key.Matches(msg, keys.Stage):
+			if err := utils.StageHunk(f.diff, f.diff.Hunks[f.hunk], f.showStaged); err != nil {
+				f.err = err.Error()
+				return v, nil
+			}
+			hunk := f.hunk
+			f.Refresh()
+			if len(f.diff.Hunks) > 0 {
+				f.hunkFocus = true
+				f.hunk = min(hunk, len(f.diff.Hunks)-1)
+				f.render()
+				f.viewport.SetYOffset(f.hunkLines[f.hunk])
+			}
+		case key.Matches(msg, keys.Back):
+			f.hunkFocus = false
+			f.render()
+		default:
+			f.viewport, cmd = f.viewport.Update(msg)
+			return v, cmd
+		}
+		return v, nil
+	}
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		if f.cursor > 0 {
+			f.cursor--
+			f.loadDiff()
+		}
+	case key.Matches(msg, keys.Down):
+		if f.cursor < len(f.entries)-1 {
+			f.cursor++
+			f.loadDiff()
+		}
+	case key.Matches(msg, keys.Stage):
+		if len(f.entries) == 0 {
+			break
+		}
+		e := f.entries[f.cursor]
+		var err error
+		if e.Unstaged() {
+			err = utils.StageFile(e.Path)
+		} else {
+			err = utils.UnstageFile(e)
+		}
+		if err != nil {
+			f.err = err.Error()
+			return v, nil
+		}
+		f.Refresh()
+	case key.Matches(msg, keys.ToggleStaged):
+		if len(f.entries) > 0 && f.entries[f.cursor].Staged() && f.entries[f.cursor].Unstaged() {
+			f.showStaged = !f.showStaged
+			f.loadDiff()
+		}
+	case key.Matches(msg, keys.Select):
+		// untracked files have no index entry to apply a hunk against
+		if len(f.diff.Hunks) > 0 && !f.entries[f.cursor].Untracked {
+			f.hunkFocus = true
+			f.render()
+			f.viewport.SetYOffset(f.hunkLines[f.hunk])
+		}
+	case key.Matches(msg, keys.Skip):
+		if v.Options.NeedsStaged() && !utils.HasStagedChanges() {
+			f.err = "nothing staged, stage at least one file to commit"
+			return v, nil
+		}
+		if len(v.history) > 0 {
+			return v.back(), nil
+		}
+		return v.navigate(SELECTION), nil
+	case key.Matches(msg, keys.Back):
+		return v.back(), nil
+	default:
+		f.viewport, cmd = f.viewport.Update(msg)
+		return v, cmd
+	}
+	return v, nil
+}
+
+func (f FilesView) View() string {
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	errStyle := term.String().Bold().Foreground(ERROR).Styled
+	staged := term.String().Foreground(SUCCESS).Styled
+
+	view := style("[Files]") + "\n"
+	if len(f.entries) == 0 {
+		view += term.String("  working tree clean").Faint().String() + "\n"
+	}
+	rows := f.listRows()
+	first := min(max(f.cursor-rows/2, 0), max(len(f.entries)-rows, 0))
+	for i := first; i < first+rows && i < len(f.entries); i++ {
+		e := f.entries[i]
+		x, y := string(e.X), string(e.Y)
+		if e.Untracked {
+			x, y = "?", "?"
+		}
+		if e.Staged() {
+			x = staged(x)
+		}
+		if e.Unstaged() {
+			y = errStyle(y)
+		}
+
+		path := e.Path
+		if e.OrigPath != "" {
+			path = e.OrigPath + " → " + e.Path
+		}
+		if f.width > 0 {
+			path = ansi.Truncate(path, f.width-5, "…")
+		}
+		if i == f.cursor {
+			path = term.String(path).Foreground(ACCENT).Underline().String()
+			view += fmt.Sprintf("> %s%s %s\n", x, y, path)
+		} else {
+			view += fmt.Sprintf("  %s%s %s\n", x, y, path)
+		}
+	}
+
+	if len(f.entries) > 0 {
+		which := "unstaged"
+		if rows < len(f.entries) {
+			which += fmt.Sprintf(" · file %d/%d", f.cursor+1, len(f.entries))
+		}
+		if f.showStaged {
+			which = "staged"
+		}
+		view += "\n" + style("[Diff]") + " " + term.String(which).Faint().String() + "\n"
+		view += f.viewport.View()
+	}
+
+	if f.err != "" {
+		view += "\n" + errStyle(f.err)
+	}
+	return view
+}
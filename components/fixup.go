@@ -0,0 +1,119 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"me.kryptk.overcommit/utils"
+)
+
+type commitDelegate struct{}
+
+func (d commitDelegate) Height() int                             { return 1 }
+func (d commitDelegate) Spacing() int                            { return 0 }
+func (d commitDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d commitDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	c := item.(utils.Commit)
+	txt := ansi.Truncate(fmt.Sprintf("%s %s", c.SHA[:7], c.Subject), m.Width()-2, "…")
+	if index == m.Index() {
+		txt = term.String("> " + txt).Foreground(ACCENT).Underline().String()
+	} else {
+		txt = term.String("  " + txt).Faint().String()
+	}
+	fmt.Fprint(w, txt)
+}
+
+// FixupView picks a recent commit and whether to fix it up, squash into
+// it or amend it.
+type FixupView struct {
+	Commit    utils.Commit
+	Kind      string
+	Cancelled bool
+	view      list.Model
+}
+
+func NewFixupView(commits []utils.Commit, kind string) FixupView {
+	items := make([]list.Item, len(commits))
+	for i, c := range commits {
+		items[i] = c
+	}
+
+	li := list.New(items, commitDelegate{}, 72, min(len(items)+4, 16))
+	li.Title = "Select the commit to change:"
+	li.SetShowStatusBar(false)
+	li.SetShowPagination(false)
+	li.SetShowHelp(false)
+	li.SetFilteringEnabled(true)
+	li.Styles.Title = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Accent)).Bold(true)
+	li.Styles.TitleBar = lipgloss.NewStyle()
+
+	return FixupView{Kind: kind, view: li}
+}
+
+func (f FixupView) Init() tea.Cmd {
+	return nil
+}
+
+func (f FixupView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		f.view.SetSize(msg.Width, min(len(f.view.Items())+4, max(msg.Height-3, 5)))
+	case tea.KeyMsg:
+		if f.view.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			f.Cancelled = true
+			return f, tea.Quit
+		case "esc":
+			if f.view.FilterState() == list.Unfiltered {
+				f.Cancelled = true
+				return f, tea.Quit
+			}
+		case "tab":
+			i := slices.Index(utils.FixupKinds, f.Kind)
+			f.Kind = utils.FixupKinds[(i+1)%len(utils.FixupKinds)]
+			return f, nil
+		case "enter":
+			if c, ok := f.view.SelectedItem().(utils.Commit); ok {
+				f.Commit = c
+				return f, tea.Quit
+			}
+			return f, nil
+		}
+	}
+
+	f.view, cmd = f.view.Update(msg)
+	return f, cmd
+}
+
+func (f FixupView) View() string {
+	if f.Commit.SHA != "" {
+		return ""
+	}
+
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	kinds := make([]string, len(utils.FixupKinds))
+	for i, k := range utils.FixupKinds {
+		if k == f.Kind {
+			kinds[i] = style(k + "!")
+		} else {
+			kinds[i] = term.String(k + "!").Faint().String()
+		}
+	}
+
+	view := fmt.Sprintf("%s : %s\n", style("[Kind]"), strings.Join(kinds, " · "))
+	view += f.view.View() + "\n"
+	view += term.String("enter commit · tab kind · / filter · esc cancel").Faint().String()
+	return view
+}
@@ -1,8 +1,8 @@
 package components
 
-import "github.com/muesli/termenv"
-
 var (
-	ACCENT       = term.Color("#8AA8F9")
-	SetTextStyle = termenv.String().Bold().Foreground(ACCENT).Styled
+	ACCENT       = term.Color(theme.Accent)
+	ERROR        = term.Color(theme.Error)
+	SUCCESS      = term.Color(theme.Success)
+	SetTextStyle = term.String().Bold().Foreground(ACCENT).Styled
 )
@@ -0,0 +1,86 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/muesli/termenv"
+	"me.kryptk.overcommit/utils"
+)
+
+// highlightHunks returns the lines of every hunk in diff without their
+// +/- marker and with syntax colours for the language of the file, or nil
+// when there is no lexer for it or the theme has no syntax style.
+func highlightHunks(diff utils.FileDiff) [][]string {
+	if theme.Syntax == "" || term == termenv.Ascii || diff.Binary {
+		return nil
+	}
+	lexer := lexers.Match(diff.Path)
+	if lexer == nil {
+		return nil
+	}
+	lexer = chroma.Coalesce(lexer)
+	style := styles.Get(theme.Syntax)
+
+	hunks := make([][]string, len(diff.Hunks))
+	for i, h := range diff.Hunks {
+		hunks[i] = highlight(lexer, style, h.Lines)
+	}
+	return hunks
+}
+
+// highlight tokenises the lines of a hunk as one piece of code, so that
+// strings and comments spanning lines are coloured right, and splits the
+// result back into lines. It gives up with nil if the lines don't match.
+func highlight(lexer chroma.Lexer, style *chroma.Style, lines []string) []string {
+	var code strings.Builder
+	for _, l := range lines {
+		// "\ No newline at end of file" is not code
+		if l != "" && l[0] != '\\' {
+			code.WriteString(l[1:])
+		}
+		code.WriteString("\n")
+	}
+
+	it, err := lexer.Tokenise(nil, code.String())
+	if err != nil {
+		return nil
+	}
+
+	out := make([]string, 0, len(lines))
+	var b strings.Builder
+	for _, tok := range it.Tokens() {
+		for i, part := range strings.Split(tok.Value, "\n") {
+			if i > 0 {
+				out = append(out, b.String())
+				b.Reset()
+			}
+			if part != "" {
+				b.WriteString(colorToken(style.Get(tok.Type), part))
+			}
+		}
+	}
+	if b.Len() > 0 {
+		out = append(out, b.String())
+	}
+	if len(out) < len(lines) {
+		return nil
+	}
+	return out[:len(lines)]
+}
+
+func colorToken(e chroma.StyleEntry, s string) string {
+	styled := term.String(s)
+	if e.Colour.IsSet() {
+		styled = styled.Foreground(term.Color(e.Colour.String()))
+	}
+	if e.Bold == chroma.Yes {
+		styled = styled.Bold()
+	}
+	if e.Italic == chroma.Yes {
+		styled = styled.Italic()
+	}
+	return styled.String()
+}
@@ -0,0 +1,153 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+	"me.kryptk.overcommit/utils"
+)
+
+// searchResults is how many fuzzy matches are listed below the query.
+const searchResults = 5
+
+// history holds the earlier subjects for one type and scope. index -1 is
+// the subject being typed, which is kept in typed while browsing.
+type history struct {
+	subjects  []string
+	loadedFor string
+	index     int
+	typed     string
+
+	searching bool
+	query     textinput.Model
+	matches   fuzzy.Matches
+	match     int
+}
+
+// load reads the history for the type and scope of v, unless it already has.
+func (h *history) load(v PageView) {
+	id := v.selected.Prefix + "\x00" + v.scope
+	if h.loadedFor == id {
+		return
+	}
+	h.subjects = utils.SubjectHistory(v.selected.Prefix, v.scope)
+	h.loadedFor = id
+	h.index = -1
+}
+
+// recall steps through the history, older with delta 1 and newer with
+// -1, and returns the subject to show.
+func (h *history) recall(delta int, current string) (string, bool) {
+	next := h.index + delta
+	if next < -1 || next >= len(h.subjects) {
+		return "", false
+	}
+	if h.index == -1 {
+		h.typed = current
+	}
+	h.index = next
+	if next == -1 {
+		return h.typed, true
+	}
+	return h.subjects[next], true
+}
+
+func (h *history) search(query string) {
+	h.matches = fuzzy.Find(query, h.subjects)
+	if query == "" {
+		h.matches = make(fuzzy.Matches, len(h.subjects))
+		for i, s := range h.subjects {
+			h.matches[i] = fuzzy.Match{Str: s, Index: i}
+		}
+	}
+	h.match = 0
+}
+
+func (c *CommitView) startSearch(v PageView) {
+	c.history.load(v)
+	c.history.searching = true
+	c.history.query = textinput.New()
+	c.history.query.Prompt = ""
+	c.history.query.Placeholder = "search earlier subjects"
+	c.history.query.Focus()
+	c.history.search("")
+}
+
+func (c *CommitView) updateSearch(msg tea.KeyMsg, v PageView) (PageView, tea.Cmd) {
+	h := &c.history
+	switch {
+	case msg.Type == tea.KeyRunes && !msg.Alt:
+	case key.Matches(msg, v.Keys.Back):
+		h.searching = false
+		return v, nil
+	case key.Matches(msg, v.Keys.Commit):
+		if h.match < len(h.matches) {
+			c.msgInput.SetValue(h.matches[h.match].Str)
+			c.msgInput.CursorEnd()
+		}
+		h.searching = false
+		return v, nil
+	case key.Matches(msg, v.Keys.HistoryPrev, v.Keys.HistorySearch):
+		if h.match < min(len(h.matches), searchResults)-1 {
+			h.match++
+		}
+		return v, nil
+	case key.Matches(msg, v.Keys.HistoryNext):
+		if h.match > 0 {
+			h.match--
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	query := h.query.Value()
+	h.query, cmd = h.query.Update(msg)
+	if h.query.Value() != query {
+		h.search(h.query.Value())
+	}
+	return v, cmd
+}
+
+func (c CommitView) searchView() string {
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	h := c.history
+
+	view := fmt.Sprintf("%s : %s", style("[History]"), h.query.View())
+	if len(h.matches) == 0 {
+		return view + "\n" + term.String("  no matches").Faint().String()
+	}
+	for i, m := range h.matches {
+		if i == searchResults {
+			break
+		}
+		line := highlightMatch(m)
+		if i == h.match {
+			view += "\n> " + line
+		} else {
+			view += "\n  " + term.String(m.Str).Faint().String()
+		}
+	}
+	return view
+}
+
+// highlightMatch marks the characters of m that matched the query.
+func highlightMatch(m fuzzy.Match) string {
+	matched := make(map[int]bool)
+	for _, i := range m.MatchedIndexes {
+		matched[i] = true
+	}
+
+	var b strings.Builder
+	for i, r := range m.Str {
+		if matched[i] {
+			b.WriteString(term.String(string(r)).Bold().Foreground(ACCENT).String())
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
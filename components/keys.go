@@ -0,0 +1,234 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"me.kryptk.overcommit/utils"
+)
+
+// KeyMap holds every remappable binding of the TUI. Actions are named in
+// the [keybindings.keys] config section by their snake_case name.
+type KeyMap struct {
+	Up            key.Binding
+	Down          key.Binding
+	Select        key.Binding
+	Skip          key.Binding
+	Back          key.Binding
+	Filter        key.Binding
+	Generate      key.Binding
+	GenerateBody  key.Binding
+	Commit        key.Binding
+	CommitBody    key.Binding
+	Stage         key.Binding
+	ToggleStaged  key.Binding
+	Files         key.Binding
+	Trailers      key.Binding
+	HistoryPrev   key.Binding
+	HistoryNext   key.Binding
+	HistorySearch key.Binding
+	Help          key.Binding
+	Quit          key.Binding
+	// Number is only shown in the help, the type selector handles 1-9
+	// itself. Yes and No answer questions and are not remappable either.
+	Number key.Binding
+	Yes    key.Binding
+	No     key.Binding
+}
+
+type keySpec struct {
+	keys []string
+	help string
+}
+
+var keyPresets = map[string]map[string]keySpec{
+	"default": {
+		"up":             {[]string{"up", "k"}, "up"},
+		"down":           {[]string{"down", "j"}, "down"},
+		"select":         {[]string{"enter", "right"}, "select"},
+		"skip":           {[]string{"tab"}, "skip"},
+		"back":           {[]string{"esc"}, "back"},
+		"filter":         {[]string{"/"}, "filter"},
+		"generate":       {[]string{"ctrl+g"}, "generate"},
+		"generate_body":  {[]string{"ctrl+b"}, "generate body"},
+		"commit":         {[]string{"enter"}, "commit"},
+		"commit_body":    {[]string{"ctrl+s"}, "commit"},
+		"stage":          {[]string{" "}, "stage/unstage"},
+		"toggle_staged":  {[]string{"s"}, "staged/unstaged diff"},
+		"files":          {[]string{"ctrl+o"}, "files"},
+		"trailers":       {[]string{"ctrl+t"}, "trailers"},
+		"history_prev":   {[]string{"up"}, "older subject"},
+		"history_next":   {[]string{"down"}, "newer subject"},
+		"history_search": {[]string{"ctrl+r"}, "search history"},
+		"help":           {[]string{"?", "f1"}, "more keys"},
+		"quit":           {[]string{"ctrl+c"}, "quit"},
+	},
+	"vim": {
+		"up":     {[]string{"k", "up", "ctrl+k"}, "up"},
+		"down":   {[]string{"j", "down", "ctrl+j"}, "down"},
+		"select": {[]string{"l", "enter", "right"}, "select"},
+		"back":   {[]string{"h", "esc"}, "back"},
+	},
+	"emacs": {
+		"up":   {[]string{"ctrl+p", "up"}, "up"},
+		"down": {[]string{"ctrl+n", "down"}, "down"},
+		"back": {[]string{"ctrl+g", "esc"}, "back"},
+		// ctrl+g means cancel in emacs, so generating moves over
+		"generate": {[]string{"alt+g"}, "generate"},
+	},
+}
+
+// NewKeyMap builds the key map from the preset in cfg, with the actions
+// in cfg.Keys overriding it. Presets only change what differs from the
+// default one.
+func NewKeyMap(cfg utils.Keybindings) (KeyMap, error) {
+	preset := cfg.Preset
+	if preset == "" {
+		preset = "default"
+	}
+	overrides, ok := keyPresets[preset]
+	if !ok {
+		return KeyMap{}, fmt.Errorf("unknown keybindings preset %q", preset)
+	}
+
+	specs := make(map[string]keySpec)
+	for action, spec := range keyPresets["default"] {
+		specs[action] = spec
+	}
+	for action, spec := range overrides {
+		specs[action] = spec
+	}
+	for action, keys := range cfg.Keys {
+		spec, ok := specs[action]
+		if !ok {
+			return KeyMap{}, fmt.Errorf("unknown key binding %q, expected one of %s", action, strings.Join(actions(), ", "))
+		}
+		specs[action] = keySpec{keys, spec.help}
+	}
+
+	bind := func(action string) key.Binding {
+		spec := specs[action]
+		names := make([]string, len(spec.keys))
+		for i, k := range spec.keys {
+			names[i] = k
+			if k == " " {
+				names[i] = "space"
+			}
+		}
+		return key.NewBinding(key.WithKeys(spec.keys...), key.WithHelp(strings.Join(names, "/"), spec.help))
+	}
+
+	return KeyMap{
+		Up:            bind("up"),
+		Down:          bind("down"),
+		Select:        bind("select"),
+		Skip:          bind("skip"),
+		Back:          bind("back"),
+		Filter:        bind("filter"),
+		Generate:      bind("generate"),
+		GenerateBody:  bind("generate_body"),
+		Commit:        bind("commit"),
+		CommitBody:    bind("commit_body"),
+		Stage:         bind("stage"),
+		ToggleStaged:  bind("toggle_staged"),
+		Files:         bind("files"),
+		Trailers:      bind("trailers"),
+		HistoryPrev:   bind("history_prev"),
+		HistoryNext:   bind("history_next"),
+		HistorySearch: bind("history_search"),
+		Help:          bind("help"),
+		Quit:          bind("quit"),
+		Number:        key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("1-9", "pick type")),
+		Yes:           key.NewBinding(key.WithKeys("y", "enter"), key.WithHelp("y/enter", "resume")),
+		No:            key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n/esc", "discard")),
+	}, nil
+}
+
+func actions() []string {
+	var names []string
+	for action := range keyPresets["default"] {
+		names = append(names, action)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pageKeys is the help.KeyMap of a single page.
+type pageKeys struct {
+	short []key.Binding
+	full  [][]key.Binding
+}
+
+func (k pageKeys) ShortHelp() []key.Binding  { return k.short }
+func (k pageKeys) FullHelp() [][]key.Binding { return k.full }
+
+// relabel returns b with a page specific description.
+func relabel(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// helpKeys lists the bindings that do something on the current page.
+func (p PageView) helpKeys() help.KeyMap {
+	k := p.Keys
+	nav := []key.Binding{k.Help, k.Quit}
+	if len(p.history) > 0 {
+		nav = append([]key.Binding{k.Back}, nav...)
+	}
+
+	switch p.Page {
+	case RESUME:
+		return pageKeys{short: []key.Binding{k.Yes, k.No, k.Quit}}
+	case FILES:
+		if p.Files != nil && p.Files.hunkFocus {
+			return pageKeys{
+				short: []key.Binding{k.Stage, k.Back, k.Help},
+				full:  [][]key.Binding{{k.Up, k.Down, k.Stage}, {k.Back, k.Help, k.Quit}},
+			}
+		}
+		hunks, next := relabel(k.Select, "hunks"), relabel(k.Skip, "continue")
+		return pageKeys{
+			short: []key.Binding{k.Stage, hunks, next, k.Help},
+			full:  [][]key.Binding{{k.Up, k.Down, k.Stage, k.ToggleStaged}, {hunks, next}, nav},
+		}
+	case SELECTION:
+		return pageKeys{
+			short: []key.Binding{k.Select, k.Number, k.Filter, k.Help},
+			full:  [][]key.Binding{{k.Up, k.Down, k.Select, k.Number}, {k.Filter, k.Files}, nav},
+		}
+	case SCOPE:
+		skip := relabel(k.Skip, "no scope")
+		return pageKeys{
+			short: []key.Binding{k.Select, skip, k.Filter, k.Help},
+			full:  [][]key.Binding{{k.Up, k.Down, k.Select, skip}, {k.Filter, k.Files}, nav},
+		}
+	case BODY:
+		return pageKeys{
+			short: []key.Binding{k.CommitBody, k.GenerateBody, k.Trailers, k.Help},
+			full:  [][]key.Binding{{k.CommitBody, k.GenerateBody}, {k.Trailers, k.Files}, nav},
+		}
+	case TRAILERS:
+		add, keys := relabel(k.Commit, "add, or commit when empty"), relabel(k.Up, "key/person")
+		complete := relabel(k.Skip, "complete")
+		return pageKeys{
+			short: []key.Binding{add, keys, complete, k.Help},
+			full:  [][]key.Binding{{add, complete}, {keys, relabel(k.Down, "key/person")}, nav},
+		}
+	default:
+		if p.Committer != nil && p.Committer.history.searching {
+			use, cancel := relabel(k.Commit, "use"), relabel(k.Back, "cancel")
+			return pageKeys{
+				short: []key.Binding{use, cancel},
+				full:  [][]key.Binding{{use, cancel}, {k.HistoryPrev, k.HistoryNext}},
+			}
+		}
+		body := relabel(k.Skip, "add body")
+		return pageKeys{
+			short: []key.Binding{k.Commit, k.Generate, body, k.Help},
+			full:  [][]key.Binding{{k.Commit, k.Generate}, {k.HistoryPrev, k.HistoryNext, k.HistorySearch}, {body, k.GenerateBody, k.Trailers, k.Files}, nav},
+		}
+	}
+}
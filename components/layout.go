@@ -0,0 +1,47 @@
+package components
+
+// compactHeight is the terminal height below which pages drop the
+// preview pane, labels and spacing, e.g. in editor embedded terminals.
+const compactHeight = 20
+
+func (p PageView) compact() bool {
+	return p.height > 0 && p.height < compactHeight
+}
+
+// resize hands every page its share of a width x height terminal. The
+// breadcrumb and help bar take what the pages don't get.
+func (p PageView) resize(width, height int) PageView {
+	p.width, p.height = width, height
+	p.Help.Width = width
+
+	chrome := 4
+	if p.compact() {
+		chrome = 2
+	}
+	rows := max(height-chrome, 3)
+
+	if p.Selector != nil {
+		p.Selector.SetSize(width, rows)
+	}
+	if p.ScopeSelector != nil {
+		p.ScopeSelector.SetSize(width, rows)
+	}
+	if p.Committer != nil {
+		p.Committer.SetWidth(width)
+	}
+	if p.Body != nil {
+		// subject, label and notes, plus the preview pane below
+		bodyRows := rows - 3
+		if !p.compact() {
+			bodyRows -= 8
+		}
+		p.Body.SetSize(width, min(max(bodyRows, 2), 20))
+	}
+	if p.Trailers != nil {
+		p.Trailers.SetWidth(width)
+	}
+	if p.Files != nil {
+		p.Files.SetSize(width, rows)
+	}
+	return p
+}
@@ -1,6 +1,11 @@
 package components
 
 import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"me.kryptk.overcommit/utils"
 )
@@ -8,20 +13,46 @@ import (
 type Page int
 
 const (
-	SELECTION = iota
+	FILES = iota
+	SELECTION
 	SCOPE
 	MSG
+	BODY
+	TRAILERS
+	RESUME
 )
 
 type PageView struct {
 	Page          Page
 	selected      utils.Key
+	gitmoji       string
 	scope         string
+	breaking      bool
+	subject       string
 	Template      utils.Template
 	Selector      *TypeSelectorView
 	ScopeSelector *ScopeSelectorView
 	Committer     *CommitView
+	Body          *BodyView
+	Trailers      *TrailersView
+	Files         *FilesView
 	FinalMessage  string
+	MsgFile       string
+	Options       utils.CommitOptions
+	Branch        string
+	Ticket        string
+	TicketSuffix  string
+	Gitmoji       utils.GitmojiConfig
+	Keys          KeyMap
+	Help          help.Model
+	history       []Page
+	saved         utils.Draft
+	pending       utils.Draft
+	saveSeq       int
+	draft         *utils.Draft
+	resumeFrom    Page
+	width         int
+	height        int
 }
 
 func (p PageView) Init() tea.Cmd {
@@ -29,25 +60,113 @@ func (p PageView) Init() tea.Cmd {
 }
 
 func (p PageView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(saveDraftMsg); ok {
+		if msg.seq == p.saveSeq {
+			p.FlushDraft()
+		}
+		return p, nil
+	}
+
+	next, cmd := p.update(msg)
+	// persist updates next, so it has to run before next is returned
+	save := next.persist()
+	return next, tea.Batch(cmd, save)
+}
+
+func (p PageView) update(msg tea.Msg) (PageView, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return p.resize(msg.Width, msg.Height), nil
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" {
+		switch {
+		case key.Matches(msg, p.Keys.Quit):
 			return p, tea.Quit
+		case msg.String() == "shift+tab":
+			if !p.busy() {
+				return p.back(), nil
+			}
+		case key.Matches(msg, p.Keys.Files):
+			if p.Files != nil && p.Page != FILES && p.Page != RESUME && !p.busy() {
+				p.Files.Refresh()
+				return p.navigate(FILES), nil
+			}
+		case key.Matches(msg, p.Keys.Help):
+			// typed characters belong to the inputs and list filters
+			if msg.Type != tea.KeyRunes || msg.Alt || !p.typing() {
+				p.Help.ShowAll = !p.Help.ShowAll
+				return p, nil
+			}
 		}
 	}
 
 	switch p.Page {
+	case RESUME:
+		return p.updateResume(msg)
+	case FILES:
+		return p.Files.Update(msg, p)
 	case SELECTION:
 		return p.Selector.Update(msg, p)
 	case SCOPE:
 		return p.ScopeSelector.Update(msg, p)
+	case BODY:
+		return p.Body.Update(msg, p)
+	case TRAILERS:
+		return p.Trailers.Update(msg, p)
 	default:
 		return p.Committer.Update(msg, p)
 	}
 }
 
+// busy reports whether a generation is in flight on one of the pages.
+func (p PageView) busy() bool {
+	return (p.Committer != nil && p.Committer.generating) || (p.Body != nil && p.Body.generating)
+}
+
+// typing reports whether the current page takes text input.
+func (p PageView) typing() bool {
+	switch p.Page {
+	case MSG, BODY, TRAILERS:
+		return true
+	case SELECTION:
+		return p.Selector != nil && p.Selector.view.FilterState() == list.Filtering
+	case SCOPE:
+		return p.ScopeSelector != nil && p.ScopeSelector.view.FilterState() == list.Filtering
+	}
+	return false
+}
+
+// navigate moves forward to page, remembering the current one for back.
+func (p PageView) navigate(page Page) PageView {
+	p.history = append(p.history, p.Page)
+	p.Page = page
+	return p
+}
+
+// back returns to the previous page. Every page keeps its own state, so
+// whatever was entered there is still in place.
+func (p PageView) back() PageView {
+	if len(p.history) == 0 {
+		return p
+	}
+	p.Page = p.history[len(p.history)-1]
+	p.history = p.history[:len(p.history)-1]
+	return p
+}
+
 func (p PageView) View() string {
+	if p.Page == RESUME {
+		return p.resumeView() + "\n\n" + p.Help.ShortHelpView(p.helpKeys().ShortHelp())
+	}
+	if p.compact() {
+		return p.breadcrumb() + "\n" + p.pageView() + "\n" + p.Help.ShortHelpView(p.helpKeys().ShortHelp())
+	}
+	return p.breadcrumb() + "\n\n" + p.pageView() + "\n\n" + p.Help.View(p.helpKeys())
+}
+
+func (p PageView) pageView() string {
 	switch p.Page {
+	case FILES:
+		return p.Files.View()
 	case SELECTION:
 		if p.Selector == nil {
 			return ""
@@ -58,7 +177,102 @@ func (p PageView) View() string {
 			return ""
 		}
 		return p.ScopeSelector.View()
+	case BODY:
+		return p.Body.View(p)
+	case TRAILERS:
+		return p.Trailers.View(p)
 	default:
 		return p.Committer.View(p)
 	}
 }
+
+func (p PageView) header() string {
+	return p.buildHeader(p.selected, p.scope, p.subject, p.gitmoji, p.breaking)
+}
+
+// buildHeader is the header for type k, scope and subject. A gitmoji
+// taken over from an existing message stands in for the one of k and is
+// kept even when the template has no %e.
+func (p PageView) buildHeader(k utils.Key, scope, subject, gitmoji string, breaking bool) string {
+	template, emoji := p.Template, k.Gitmoji(p.Gitmoji.Format)
+	if gitmoji != "" {
+		emoji = gitmoji
+		for _, t := range []*string{&template.Normal, &template.Region} {
+			if !strings.Contains(*t, "%e") {
+				*t = "%e " + *t
+			}
+		}
+	}
+	return utils.BuildCommitMessage(template, k.Prefix, scope, p.ticketed(subject), p.Ticket, emoji, breaking)
+}
+
+// ticketed adds TicketSuffix to a subject that does not mention the
+// ticket yet.
+func (p PageView) ticketed(subject string) string {
+	if p.TicketSuffix == "" || subject == "" || strings.Contains(subject, p.Ticket) {
+		return subject
+	}
+	return subject + p.TicketSuffix
+}
+
+// message is the full commit message for subject and body as it will be
+// handed to git.
+func (p PageView) message(subject, body string) string {
+	msg := p.buildHeader(p.selected, p.scope, subject, p.gitmoji, p.breaking)
+	if body = strings.TrimSpace(body); body != "" {
+		msg += "\n\n" + body
+	}
+	return msg
+}
+
+// finish assembles the final message from the entered parts and quits.
+// With nothing staged it opens the files page instead, since git commit
+// would only fail after the TUI has exited.
+func (p PageView) finish() (PageView, tea.Cmd) {
+	if p.MsgFile == "" && p.Options.NeedsStaged() && p.Files != nil && !utils.HasStagedChanges() {
+		p.Files.Refresh()
+		p.Files.err = "nothing staged, stage at least one file to commit"
+		return p.navigate(FILES), nil
+	}
+
+	body := ""
+	if p.Body != nil {
+		body = p.Body.Value()
+	}
+	msg := p.message(p.subject, body)
+	if p.Trailers != nil {
+		var err error
+		if msg, err = utils.AddTrailers(msg, p.Trailers.Trailers()); err != nil {
+			p.Trailers.err = err.Error()
+			if p.Page != TRAILERS {
+				p = p.navigate(TRAILERS)
+			}
+			return p, nil
+		}
+	}
+	if violations := utils.LintTicket(p.selected.Prefix, msg, p.lint()); len(violations) > 0 {
+		p.fail(strings.Join(violations, ", "))
+		return p, nil
+	}
+	p.FinalMessage = msg
+	return p, tea.Quit
+}
+
+func (p PageView) lint() utils.Lint {
+	if p.Committer == nil {
+		return utils.Lint{}
+	}
+	return p.Committer.lint
+}
+
+// fail shows err on the current page.
+func (p PageView) fail(err string) {
+	switch {
+	case p.Page == BODY && p.Body != nil:
+		p.Body.err = err
+	case p.Page == TRAILERS && p.Trailers != nil:
+		p.Trailers.err = err
+	case p.Committer != nil:
+		p.Committer.err = err
+	}
+}
@@ -0,0 +1,91 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"me.kryptk.overcommit/utils"
+)
+
+// preview renders the message that will be passed to git for the given
+// subject and body, with lint violations marked inline.
+func (p PageView) preview(subject, body string, lint utils.Lint) string {
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	errStyle := term.String().Bold().Foreground(ERROR).Styled
+	faint := func(s string) string { return term.String(s).Faint().String() }
+
+	template := fmt.Sprintf("normal template %q (no scope)", p.Template.Normal)
+	if p.scope != "" {
+		template = fmt.Sprintf("region template %q (scope %q)", p.Template.Region, p.scope)
+	}
+
+	// the template only does plain replacements, so a styled subject
+	// lands in the same place as the plain one
+	msg := p.message(markSubject(subject, lint, errStyle), markBody(body, lint, errStyle))
+	plain := p.message(subject, body)
+	if p.Trailers != nil {
+		msg = utils.FormatTrailers(msg, p.Trailers.Trailers())
+		plain = utils.FormatTrailers(plain, p.Trailers.Trailers())
+	}
+
+	view := fmt.Sprintf("%s · %s", style("[Preview]"), faint(template))
+	if p.width > 0 {
+		view = ansi.Truncate(view, p.width, "…")
+	}
+	view += "\n"
+	for _, line := range strings.Split(msg, "\n") {
+		if p.width > 2 {
+			line = ansi.Wrap(line, p.width-2, "")
+		}
+		for _, l := range strings.Split(line, "\n") {
+			view += faint("│ ") + l + "\n"
+		}
+	}
+
+	// an empty subject is the starting point, not a mistake worth shouting about
+	var violations []string
+	if subject != "" {
+		violations = utils.LintSubject(p.ticketed(subject), lint)
+		violations = append(violations, utils.LintTicket(p.selected.Prefix, plain, lint)...)
+	}
+	violations = append(violations, utils.LintBody(body, lint)...)
+	for _, v := range violations {
+		view += errStyle("✗ "+v) + "\n"
+	}
+	return strings.TrimSuffix(view, "\n")
+}
+
+// markSubject highlights the characters past the length limit and a
+// disallowed trailing period.
+func markSubject(subject string, lint utils.Lint, mark func(string) string) string {
+	tail := ""
+	if !lint.AllowTrailingPeriod && strings.HasSuffix(subject, ".") {
+		subject, tail = strings.TrimSuffix(subject, "."), mark(".")
+	}
+	return markOverflow(subject, lint.MaxSubjectLength, mark) + tail
+}
+
+// markBody highlights the part of every line past the body line width,
+// leaving unbreakable lines such as long URLs alone like LintBody does.
+func markBody(body string, lint utils.Lint, mark func(string) string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.Contains(strings.TrimSpace(line), " ") {
+			lines[i] = markOverflow(line, lint.BodyLineWidth, mark)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markOverflow(s string, limit int, mark func(string) string) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + mark(s[cut:])
+}
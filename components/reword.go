@@ -0,0 +1,96 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"me.kryptk.overcommit/utils"
+)
+
+// RewordView walks through proposed rewords one at a time, letting the
+// user edit, accept or skip each of them.
+type RewordView struct {
+	Rewords   []utils.Reword
+	Cancelled bool
+	index     int
+	input     textinput.Model
+	lint      utils.Lint
+	err       string
+}
+
+func NewRewordView(rewords []utils.Reword, lint utils.Lint) RewordView {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.Focus()
+	if len(rewords) > 0 {
+		ti.SetValue(rewords[0].New)
+	}
+
+	return RewordView{Rewords: rewords, input: ti, lint: lint}
+}
+
+func (r RewordView) Init() tea.Cmd {
+	return nil
+}
+
+func (r RewordView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			r.Cancelled = true
+			return r, tea.Quit
+		case "enter":
+			h, ok := utils.ParseHeader(r.input.Value())
+			if !ok {
+				r.err = "expected type(scope): subject"
+				return r, nil
+			}
+			if violations := utils.LintSubject(h.Subject, r.lint); len(violations) > 0 {
+				r.err = strings.Join(violations, ", ")
+				return r, nil
+			}
+			r.Rewords[r.index].New = r.input.Value()
+			return r.next()
+		case "tab":
+			r.Rewords[r.index].Skipped = true
+			return r.next()
+		}
+	}
+
+	r.input, cmd = r.input.Update(msg)
+	return r, cmd
+}
+
+func (r RewordView) next() (tea.Model, tea.Cmd) {
+	r.err = ""
+	r.index++
+	if r.index >= len(r.Rewords) {
+		return r, tea.Quit
+	}
+	r.input.SetValue(r.Rewords[r.index].New)
+	return r, nil
+}
+
+func (r RewordView) View() string {
+	if r.index >= len(r.Rewords) {
+		return ""
+	}
+
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	errStyle := term.String().Bold().Foreground(ERROR).Styled
+	current := r.Rewords[r.index]
+
+	view := fmt.Sprintf("%s %d/%d : %s\n", style("[Commit]"), r.index+1, len(r.Rewords), current.SHA[:7])
+	view += fmt.Sprintf("%s : %s\n", style("[Old]"), current.Old)
+	view += fmt.Sprintf("%s : %s\n", style("[New]"), r.input.View())
+	view += term.String("enter accept · tab skip · esc cancel").Faint().String()
+
+	if r.err != "" {
+		view += "\n" + errStyle(r.err)
+	}
+	return view
+}
@@ -4,10 +4,11 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/termenv"
+	"github.com/charmbracelet/x/ansi"
 )
 
 type scopeItem string
@@ -22,11 +23,12 @@ func (d scopeDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d scopeDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
 	s := string(item.(scopeItem))
+	s = ansi.Truncate(s, m.Width()-2, "…")
 	txt := fmt.Sprintf("  %s", s)
 	if index == m.Index() {
-		txt = termenv.String(fmt.Sprintf("> %s", s)).Foreground(term.Color("#8AA8F9")).Underline().String()
+		txt = term.String(fmt.Sprintf("> %s", s)).Foreground(ACCENT).Underline().String()
 	} else {
-		txt = termenv.String(txt).Faint().String()
+		txt = term.String(txt).Faint().String()
 	}
 	fmt.Fprint(w, txt)
 }
@@ -35,7 +37,7 @@ type ScopeSelectorView struct {
 	view list.Model
 }
 
-func NewScopeSelector(scopes []string) ScopeSelectorView {
+func NewScopeSelector(scopes []string, km KeyMap) ScopeSelectorView {
 	items := make([]list.Item, len(scopes))
 	for i, s := range scopes {
 		items[i] = scopeItem(s)
@@ -46,40 +48,53 @@ func NewScopeSelector(scopes []string) ScopeSelectorView {
 		height = 12
 	}
 	li := list.New(items, scopeDelegate{}, 40, height)
-	li.Title = "Select scope (Esc to skip):"
+	li.Title = "Select scope:"
 	li.SetShowTitle(true)
 	li.SetShowStatusBar(false)
 	li.SetShowPagination(false)
 	li.SetShowHelp(false)
 	li.SetFilteringEnabled(true)
-	li.Styles.Title = lipgloss.NewStyle().Foreground(lipgloss.Color("#8AA8F9")).Bold(true)
+	li.Styles.Title = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Accent)).Bold(true)
 	li.Styles.TitleBar = lipgloss.NewStyle()
+	li.KeyMap.CursorUp = km.Up
+	li.KeyMap.CursorDown = km.Down
+	li.KeyMap.Filter = km.Filter
 
 	return ScopeSelectorView{view: li}
 }
 
+// Select moves the cursor to scope, adding it to the list when missing.
+func (s *ScopeSelectorView) Select(scope string) {
+	for i, item := range s.view.Items() {
+		if string(item.(scopeItem)) == scope {
+			s.view.Select(i)
+			return
+		}
+	}
+	s.view.InsertItem(0, scopeItem(scope))
+	s.view.Select(0)
+}
+
 func (s *ScopeSelectorView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		return v, nil
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			if s.view.FilterState() == list.Filtering {
-				break
-			}
+		if s.view.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, v.Keys.Select):
 			if len(s.view.Items()) > 0 && s.view.Index() < len(s.view.Items()) {
 				v.scope = string(s.view.SelectedItem().(scopeItem))
 			}
-			v.Page = MSG
-			return v, nil
-		case "esc":
-			if s.view.FilterState() != list.Filtering {
-				v.scope = ""
-				v.Page = MSG
-				return v, nil
+			return v.navigate(MSG), nil
+		case key.Matches(msg, v.Keys.Skip):
+			v.scope = ""
+			return v.navigate(MSG), nil
+		case key.Matches(msg, v.Keys.Back):
+			if s.view.FilterState() == list.Unfiltered {
+				return v.back(), nil
 			}
 		}
 	}
@@ -88,6 +103,10 @@ func (s *ScopeSelectorView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd)
 	return v, cmd
 }
 
+func (s *ScopeSelectorView) SetSize(width, height int) {
+	s.view.SetSize(width, min(len(s.view.Items())+4, height))
+}
+
 func (s ScopeSelectorView) View() string {
 	return s.view.View()
 }
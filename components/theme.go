@@ -0,0 +1,91 @@
+package components
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"me.kryptk.overcommit/utils"
+)
+
+// Theme is a set of colors, either hex values or ANSI color numbers, and
+// the chroma style used to highlight diffs.
+type Theme struct {
+	Accent  string
+	Error   string
+	Success string
+	Syntax  string
+}
+
+var themes = map[string]Theme{
+	"dark":  {Accent: "#8AA8F9", Error: "#FF5555", Success: "#50FA7B", Syntax: "github-dark"},
+	"light": {Accent: "#3451B2", Error: "#C4262E", Success: "#1A7F37", Syntax: "github"},
+	// the bright ANSI colors follow the terminal's own palette, which is
+	// what users of high contrast setups have tuned, so no syntax colors
+	"high-contrast": {Accent: "14", Error: "9", Success: "10"},
+}
+
+var profiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"ansi256":   termenv.ANSI256,
+	"ansi":      termenv.ANSI,
+	"ascii":     termenv.Ascii,
+}
+
+var (
+	term  = termenv.EnvColorProfile()
+	theme = themes["dark"]
+)
+
+// SetTheme detects the color profile of the terminal and picks the theme
+// from cfg. It has to run before any view is created. NO_COLOR always
+// wins over the configured profile.
+func SetTheme(cfg utils.ThemeConfig) error {
+	profile := termenv.EnvColorProfile()
+	if cfg.Profile != "" && cfg.Profile != "auto" && os.Getenv("NO_COLOR") == "" {
+		p, ok := profiles[cfg.Profile]
+		if !ok {
+			return fmt.Errorf("unknown color profile %q", cfg.Profile)
+		}
+		profile = p
+	}
+
+	name := cfg.Name
+	if name == "" || name == "auto" {
+		name = "dark"
+		if profile != termenv.Ascii && !termenv.HasDarkBackground() {
+			name = "light"
+		}
+	}
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+	if cfg.Accent != "" {
+		t.Accent = cfg.Accent
+	}
+	if cfg.Error != "" {
+		t.Error = cfg.Error
+	}
+	if cfg.Success != "" {
+		t.Success = cfg.Success
+	}
+	switch cfg.Syntax {
+	case "":
+	case "none":
+		t.Syntax = ""
+	default:
+		if _, ok := styles.Registry[cfg.Syntax]; !ok {
+			return fmt.Errorf("unknown syntax style %q", cfg.Syntax)
+		}
+		t.Syntax = cfg.Syntax
+	}
+
+	term, theme = profile, t
+	ACCENT, ERROR, SUCCESS = term.Color(t.Accent), term.Color(t.Error), term.Color(t.Success)
+	SetTextStyle = term.String().Bold().Foreground(ACCENT).Styled
+	lipgloss.SetColorProfile(profile)
+	return nil
+}
@@ -0,0 +1,175 @@
+package components
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sahilm/fuzzy"
+	"me.kryptk.overcommit/utils"
+)
+
+// TrailersView adds git trailers such as Co-authored-by below the body,
+// completing people from the roster and the repository's authors.
+type TrailersView struct {
+	keys     []string
+	key      int
+	input    textinput.Model
+	roster   []string
+	people   []string
+	loaded   bool
+	matches  fuzzy.Matches
+	match    int
+	trailers []utils.Trailer
+	err      string
+}
+
+func NewTrailersView(cfg utils.TrailersConfig) TrailersView {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.Focus()
+
+	t := TrailersView{keys: cfg.Keys, input: ti, roster: cfg.Roster}
+	t.setKey(0)
+	return t
+}
+
+// Trailers are the trailers added so far, in order.
+func (t TrailersView) Trailers() []utils.Trailer {
+	return t.trailers
+}
+
+// Add adds t unless it is there already.
+func (t *TrailersView) Add(tr utils.Trailer) {
+	if !slices.Contains(t.trailers, tr) {
+		t.trailers = append(t.trailers, tr)
+	}
+}
+
+// load reads the people to complete from once, when the page is opened.
+func (t *TrailersView) load() {
+	if !t.loaded {
+		t.people = utils.People(t.roster)
+		t.loaded = true
+	}
+}
+
+func (t *TrailersView) setKey(i int) {
+	if len(t.keys) == 0 {
+		return
+	}
+	t.key = (i + len(t.keys)) % len(t.keys)
+	t.input.Placeholder = "value"
+	if utils.PersonTrailer(t.keys[t.key]) {
+		t.input.Placeholder = "Name <email>"
+	}
+	t.complete()
+}
+
+// complete matches the typed value against the known people, for person
+// trailers only.
+func (t *TrailersView) complete() {
+	t.matches, t.match = nil, 0
+	value := strings.TrimSpace(t.input.Value())
+	if value != "" && len(t.keys) > 0 && utils.PersonTrailer(t.keys[t.key]) {
+		t.matches = fuzzy.Find(value, t.people)
+	}
+}
+
+func (t *TrailersView) Update(m tea.Msg, v PageView) (PageView, tea.Cmd) {
+	var cmd tea.Cmd
+
+	msg, ok := m.(tea.KeyMsg)
+	if ok && (msg.Type != tea.KeyRunes || msg.Alt) {
+		switch {
+		case key.Matches(msg, v.Keys.Back):
+			return v.back(), nil
+		case key.Matches(msg, v.Keys.Up, v.Keys.Down):
+			delta := 1
+			if key.Matches(msg, v.Keys.Up) {
+				delta = -1
+			}
+			// up and down walk the completions while there are any
+			if n := min(len(t.matches), searchResults); n > 0 {
+				t.match = (t.match + delta + n) % n
+			} else {
+				t.setKey(t.key + delta)
+			}
+			return v, nil
+		case key.Matches(msg, v.Keys.Skip):
+			if len(t.matches) > 0 {
+				t.input.SetValue(t.matches[t.match].Str)
+				t.input.CursorEnd()
+				t.matches = nil
+			}
+			return v, nil
+		case key.Matches(msg, v.Keys.Commit):
+			value := strings.TrimSpace(t.input.Value())
+			if value == "" {
+				return v.finish()
+			}
+			if len(t.keys) > 0 {
+				t.trailers = append(t.trailers, utils.Trailer{Key: t.keys[t.key], Value: value})
+			}
+			t.input.Reset()
+			t.complete()
+			return v, nil
+		case msg.Type == tea.KeyBackspace && t.input.Value() == "" && len(t.trailers) > 0:
+			t.trailers = t.trailers[:len(t.trailers)-1]
+			return v, nil
+		}
+	}
+
+	before := t.input.Value()
+	t.input, cmd = t.input.Update(m)
+	if t.input.Value() != before {
+		t.complete()
+	}
+	return v, cmd
+}
+
+// SetWidth fits the input behind the trailer key.
+func (t *TrailersView) SetWidth(width int) {
+	t.input.Width = max(width-len("[Trailer] Co-authored-by: ")-1, 10)
+}
+
+func (t TrailersView) View(v PageView) string {
+	style := term.String().Bold().Foreground(ACCENT).Styled
+	errStyle := term.String().Bold().Foreground(ERROR).Styled
+
+	view := ""
+	for _, tr := range t.trailers {
+		view += term.String("  "+tr.String()).Faint().String() + "\n"
+	}
+
+	if len(t.keys) == 0 {
+		return view + errStyle("no trailer keys configured")
+	}
+	view += fmt.Sprintf("%s %s: %s", style("[Trailer]"), t.keys[t.key], t.input.View())
+	for i, m := range t.matches {
+		if i == searchResults {
+			break
+		}
+		if i == t.match {
+			view += "\n> " + highlightMatch(m)
+		} else {
+			view += "\n  " + term.String(m.Str).Faint().String()
+		}
+	}
+
+	if t.err != "" {
+		view += "\n" + errStyle(t.err)
+	}
+
+	if !v.compact() {
+		body := ""
+		if v.Body != nil {
+			body = v.Body.Value()
+		}
+		view += "\n\n" + v.preview(v.subject, body, v.lint())
+	}
+	return view
+}
@@ -5,18 +5,15 @@ import (
 	"io"
 	"strconv"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/termenv"
+	"github.com/charmbracelet/x/ansi"
 	"me.kryptk.overcommit/utils"
 )
 
-var (
-	term = termenv.TrueColor
-)
-
-func NewTypeSelector(keys []utils.Key) TypeSelectorView {
+func NewTypeSelector(keys []utils.Key, km KeyMap) TypeSelectorView {
 	// just a type coercion
 	items := keysToItems(keys)
 
@@ -27,8 +24,11 @@ func NewTypeSelector(keys []utils.Key) TypeSelectorView {
 	li.SetShowPagination(false)
 	li.SetShowHelp(false)
 	li.SetFilteringEnabled(true)
-	li.Styles.Title = lipgloss.NewStyle().Foreground(lipgloss.Color("#8AA8F9")).Bold(true)
+	li.Styles.Title = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Accent)).Bold(true)
 	li.Styles.TitleBar = lipgloss.NewStyle()
+	li.KeyMap.CursorUp = km.Up
+	li.KeyMap.CursorDown = km.Down
+	li.KeyMap.Filter = km.Filter
 	return TypeSelectorView{
 		view: li,
 	}
@@ -48,12 +48,17 @@ func (l listDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 		return
 	}
 
-	txt := fmt.Sprintf("(%s) - %s [%d]", i.Prefix, i.Description, index+1)
+	// the unicode form even with shortcodes configured, it reads better
+	prefix := fmt.Sprintf("(%s)", i.Prefix)
+	if emoji := i.Gitmoji("unicode"); emoji != "" {
+		prefix += " " + emoji
+	}
+	txt := ansi.Truncate(fmt.Sprintf("%s - %s [%d]", prefix, i.Description, index+1), m.Width(), "…")
 
 	if selected {
-		txt = termenv.String(txt).Foreground(term.Color("#8AA8F9")).Underline().String()
+		txt = term.String(txt).Foreground(ACCENT).Underline().String()
 	} else {
-		txt = termenv.String(txt).Faint().String()
+		txt = term.String(txt).Faint().String()
 	}
 
 	_, _ = fmt.Fprint(w, txt)
@@ -69,6 +74,31 @@ func (l listDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd {
 	return nil
 }
 
+func (tsv TypeSelectorView) find(prefix string) (utils.Key, bool) {
+	for _, item := range tsv.view.Items() {
+		if k := item.(utils.Key); k.Prefix == prefix {
+			return k, true
+		}
+	}
+	return utils.Key{}, false
+}
+
+// Select moves the cursor to the type with prefix, if there is one.
+func (tsv *TypeSelectorView) Select(prefix string) {
+	for i, item := range tsv.view.Items() {
+		if item.(utils.Key).Prefix == prefix {
+			tsv.view.Select(i)
+			return
+		}
+	}
+}
+
+// SetSize fits the list into width x height, showing all types when
+// there is room for them.
+func (tsv *TypeSelectorView) SetSize(width, height int) {
+	tsv.view.SetSize(width, min(len(tsv.view.Items())+4, height))
+}
+
 func (tsv TypeSelectorView) View() string {
 	return tsv.view.View()
 }
@@ -77,23 +107,26 @@ func (tsv *TypeSelectorView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd)
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		return v, nil
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "enter", tea.KeyRight.String():
-			v.selected = tsv.view.SelectedItem().(utils.Key)
-			v.Page = SCOPE
+		if tsv.view.FilterState() == list.Filtering && msg.Type == tea.KeyRunes {
+			break
+		}
+		switch {
+		case key.Matches(msg, v.Keys.Select):
+			// a filter matching nothing leaves no item to select
+			if k, ok := tsv.view.SelectedItem().(utils.Key); ok {
+				return v.choose(k).navigate(SCOPE), nil
+			}
 			return v, nil
+		case key.Matches(msg, v.Keys.Back) && len(v.history) > 0 && tsv.view.FilterState() == list.Unfiltered:
+			return v.back(), nil
 		default:
-			index, err := strconv.Atoi(keypress)
+			index, err := strconv.Atoi(msg.String())
 			if err != nil {
 				break
 			}
 			if index >= 1 && index <= len(tsv.view.Items()) {
-				v.selected = tsv.view.Items()[index-1].(utils.Key)
-				v.Page = SCOPE
-				return v, nil
+				return v.choose(tsv.view.Items()[index-1].(utils.Key)).navigate(SCOPE), nil
 			}
 		}
 	}
@@ -103,6 +136,16 @@ func (tsv *TypeSelectorView) Update(msg tea.Msg, v PageView) (PageView, tea.Cmd)
 	return v, cmd
 }
 
+// choose selects k, dropping a gitmoji taken over from an amended message
+// once the type changes.
+func (p PageView) choose(k utils.Key) PageView {
+	if k.Prefix != p.selected.Prefix {
+		p.gitmoji = ""
+	}
+	p.selected = k
+	return p
+}
+
 func keysToItems(keys []utils.Key) []list.Item {
 	items := make([]list.Item, len(keys))
 
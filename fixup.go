@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"me.kryptk.overcommit/components"
+	"me.kryptk.overcommit/utils"
+)
+
+func runFixup(args []string) error {
+	fs := flag.NewFlagSet("fixup", flag.ContinueOnError)
+	n := fs.Int("n", 30, "number of recent commits to choose from")
+	squash := fs.Bool("squash", false, "start with squash! instead of fixup!")
+	amend := fs.Bool("amend", false, "start with amend! instead of fixup!")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	c, err := utils.LoadConfig(config)
+	if err != nil {
+		return err
+	}
+	if err := components.SetTheme(c.Theme); err != nil {
+		return err
+	}
+
+	commits, err := utils.RecentCommits(*n)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return errors.New("no commits to fix up")
+	}
+
+	kind := "fixup"
+	switch {
+	case *squash:
+		kind = "squash"
+	case *amend:
+		kind = "amend"
+	}
+
+	finalModel, err := tea.NewProgram(components.NewFixupView(commits, kind)).Run()
+	if err != nil {
+		return err
+	}
+
+	result := finalModel.(components.FixupView)
+	if result.Cancelled {
+		return exitStatus(1)
+	}
+	if result.Kind != "amend" && !utils.HasStagedChanges() {
+		return errors.New("nothing staged, stage the changes for the " + result.Kind + " commit first")
+	}
+
+	// fixups are signed and signed off like any other commit
+	commitArgs := append(utils.FixupArgs(result.Kind, result.Commit.SHA), c.Commit.Args()...)
+	// squash! and amend! open the editor for the message, so git needs the terminal
+	if err := utils.GitPassthrough(os.Stdin, commitArgs...); err != nil {
+		// git has already said what went wrong
+		return exitStatus(utils.ExitCode(err))
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"me.kryptk.overcommit/utils"
+)
+
+func runLLM(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "prompt":
+			return runLLMPrompt(args[1:])
+		case "usage":
+			return runLLMUsage(args[1:])
+		}
+		fmt.Fprintf(os.Stderr, "unknown llm command: %s\n", args[0])
+	}
+
+	fmt.Fprintln(os.Stderr, "usage: overcommit llm prompt [--dry-run] [--type TYPE] [--scope SCOPE] [--body --subject SUBJECT]")
+	fmt.Fprintln(os.Stderr, "       overcommit llm usage [--by day|model|repo]")
+	return errUsage
+}
+
+func runLLMPrompt(args []string) error {
+	fs := flag.NewFlagSet("llm prompt", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the final prompt without calling the backend")
+	commitType := fs.String("type", "feat", "commit type")
+	scope := fs.String("scope", "", "commit scope")
+	body := fs.Bool("body", false, "build the body prompt instead of the subject prompt")
+	subject := fs.String("subject", "", "subject the body is written for, with --body")
+	noCache := fs.Bool("no-cache", false, "always ask the LLM instead of using cached generations")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	c, err := utils.LoadConfig(config)
+	if err != nil {
+		return err
+	}
+	if *noCache {
+		c.LLM.Cache.Disabled = true
+	}
+
+	prepare := func() (utils.Prompt, int, error) {
+		if *body {
+			return utils.PrepareBodyPrompt(c.LLM, *commitType, *scope, *subject)
+		}
+		return utils.PreparePrompt(c.LLM, *commitType, *scope)
+	}
+	prompt, redacted, err := prepare()
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Println(prompt)
+		fmt.Printf("\n(prompt hash %s)\n", utils.PromptHash(prompt))
+		if redacted > 0 {
+			fmt.Printf("(%d secrets redacted)\n", redacted)
+		}
+		return nil
+	}
+
+	client := utils.NewLLMClient(c.LLM)
+	var resp utils.Response
+	if *body {
+		resp, err = utils.GenerateBody(client, prompt, c.Lint)
+	} else {
+		resp, err = utils.GenerateSubject(client, prompt, c.Lint, c.LLM.Retries)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.Text)
+	fmt.Fprintf(os.Stderr, "(via %s, cached: %t)\n", resp.Backend, resp.Cached)
+	return nil
+}
+
+func runLLMUsage(args []string) error {
+	fs := flag.NewFlagSet("llm usage", flag.ContinueOnError)
+	by := fs.String("by", "day", "group totals by day, model or repo")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	c, err := utils.LoadConfig(config)
+	if err != nil {
+		return err
+	}
+
+	records, err := utils.ReadUsage(c.LLM.Usage.LedgerPath())
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println("no usage recorded yet")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tcalls\tinput\toutput\tcost (USD)\t\n", strings.ToUpper(*by))
+
+	var total utils.UsageTotal
+	for _, t := range utils.SummarizeUsage(records, *by) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.4f\t\n", t.Key, t.Calls, t.InputTokens, t.OutputTokens, t.Cost)
+		total.Calls += t.Calls
+		total.InputTokens += t.InputTokens
+		total.OutputTokens += t.OutputTokens
+		total.Cost += t.Cost
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%.4f\t\n", total.Calls, total.InputTokens, total.OutputTokens, total.Cost)
+	return w.Flush()
+}
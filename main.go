@@ -2,11 +2,15 @@ package main
 
 import (
 	_ "embed"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"os"
-	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 	"me.kryptk.overcommit/components"
 	"me.kryptk.overcommit/utils"
@@ -15,81 +19,273 @@ import (
 //go:embed config.toml
 var config string
 
+// exitStatus ends overcommit with a status once the failure has been
+// reported, so main prints nothing more.
+type exitStatus int
+
+func (e exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// errUsage is returned after printing usage for bad arguments.
+const errUsage = exitStatus(2)
+
 func main() {
-	_, err := os.ReadDir(os.ExpandEnv("$PWD/.git"))
-	if err != nil {
-		fmt.Println("not a git repository")
+	err := run(os.Args[1:])
+	if err == nil {
 		return
 	}
 
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
+	var status exitStatus
+	if errors.As(err, &status) {
+		os.Exit(int(status))
+	}
+	fmt.Fprintln(os.Stderr, "overcommit:", err)
+	os.Exit(utils.ExitCode(err))
+}
+
+func run(args []string) error {
+	if _, err := os.ReadDir(os.ExpandEnv("$PWD/.git")); err != nil {
+		return errors.New("not a git repository")
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
 		case "-i", "--init":
-			initRepo()
-			return
+			return initRepo()
 		case "--alias":
-			exec.Command("git", "config", "--global", "alias.c", "!overcommit").Run()
+			if _, err := utils.Git(nil, "config", "--global", "alias.c", "!overcommit"); err != nil {
+				return err
+			}
 			fmt.Println("done. use: git c")
-			return
+			return nil
+		case "llm":
+			return runLLM(args[1:])
+		case "reword":
+			return runReword(args[1:])
+		case "fixup":
+			return runFixup(args[1:])
 		}
 	}
 
-	runTUI()
+	return runTUI(args)
 }
 
-func initRepo() {
+func initRepo() error {
+	c, err := utils.LoadConfig(config)
+	if err != nil {
+		return err
+	}
+	var types []string
+	for _, k := range c.Keys {
+		types = append(types, regexp.QuoteMeta(k.Prefix))
+	}
+
 	hookDir := ".githooks"
-	os.MkdirAll(hookDir, 0755)
+	if err := os.MkdirAll(hookDir, 0755); err != nil {
+		return err
+	}
 
-	hook := `#!/bin/sh
+	hook := fmt.Sprintf(`#!/bin/sh
 msg=$(head -1 "$1")
-if ! echo "$msg" | grep -qE '^(feat|fix|docs|style|refactor|test|chore)(\(.+\))?: .+'; then
+# fixup!, squash! and amend! commits made for git rebase --autosquash, and
+# a gitmoji in front of the type, as :sparkles: or the emoji itself
+if ! echo "$msg" | grep -qE '^((fixup|squash|amend)! )*((:[a-z0-9_+-]+:|[^ -~]+) ?)?(%s)(\(.+\))?!?: .+'; then
   echo "bad commit message: $msg"
   echo ""
   echo "expected: type(scope): message"
-  echo "types: feat|fix|docs|style|refactor|test|chore"
+  echo "types: %s"
   echo ""
   echo "use 'overcommit' for easy conventional commits"
   exit 1
 fi
-`
-	os.WriteFile(hookDir+"/commit-msg", []byte(hook), 0755)
-	exec.Command("git", "config", "core.hooksPath", hookDir).Run()
+`, strings.Join(types, "|"), strings.Join(types, "|"))
+	if err := os.WriteFile(hookDir+"/commit-msg", []byte(hook), 0755); err != nil {
+		return err
+	}
+	if _, err := utils.Git(nil, "config", "core.hooksPath", hookDir); err != nil {
+		return err
+	}
 
 	fmt.Println("done. commit .githooks/ to enforce for team")
+	return nil
 }
 
-func runTUI() {
+func runTUI(args []string) error {
 	c, err := utils.LoadConfig(config)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	// the [commit] table of the config provides the defaults
+	opts := c.Commit
+	fs := flag.NewFlagSet("overcommit", flag.ContinueOnError)
+	noCache := fs.Bool("no-cache", false, "always ask the LLM instead of using cached generations")
+	fs.BoolVar(&opts.Amend, "amend", false, "edit the last commit, starting from its message")
+	fs.BoolVar(&opts.Sign, "S", opts.Sign, "GPG or SSH sign the commit")
+	fs.BoolVar(&opts.Signoff, "signoff", opts.Signoff, "add a Signed-off-by trailer")
+	fs.BoolVar(&opts.NoVerify, "no-verify", opts.NoVerify, "skip the pre-commit and commit-msg hooks")
+	fs.StringVar(&opts.Author, "author", opts.Author, "override the commit author, as \"Name <email>\"")
+	fs.StringVar(&opts.Date, "date", opts.Date, "override the author date")
+	fs.BoolVar(&opts.AllowEmpty, "allow-empty", opts.AllowEmpty, "allow a commit without changes")
+	fs.BoolVar(&opts.All, "all", opts.All, "commit all modified tracked files, staged or not")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if *noCache {
+		c.LLM.Cache.Disabled = true
+	}
+
+	if err := components.SetTheme(c.Theme); err != nil {
+		return err
+	}
+	keys, err := components.NewKeyMap(c.Keybindings)
+	if err != nil {
+		return err
+	}
+
+	selector := components.NewTypeSelector(c.Keys, keys)
+	scopeSelector := components.NewScopeSelector(utils.GetScopes(), keys)
+	committer := components.NewCommitView(c.Lint, c.LLM)
+	body := components.NewBodyView(c.Lint, c.LLM)
+	trailers := components.NewTrailersView(c.Trailers)
+	files := components.NewFilesView()
+
+	branch := utils.GetCurrentBranch()
+	ticket, err := utils.TicketFromBranch(branch, c.Branch)
+	if err != nil {
+		return err
+	}
+	ticketSuffix := ""
+	switch {
+	case ticket == "":
+	case c.Branch.Ticket == "trailer":
+		trailers.Add(utils.Trailer{Key: c.Branch.TicketTrailer, Value: ticket})
+	case c.Branch.Ticket == "suffix":
+		ticketSuffix = strings.ReplaceAll(c.Branch.TicketSuffix, "%t", ticket)
 	}
 
-	selector := components.NewTypeSelector(c.Keys)
-	scopeSelector := components.NewScopeSelector(utils.GetScopes())
-	committer := components.NewCommitView(c.Lint.MaxSubjectLength, c.LLM)
+	// a fix/ branch says more about the type than the staged files do
+	if typ, ok := utils.TypeFromBranch(branch, c.Branch); ok {
+		selector.Select(typ)
+	} else if c.LLM.Backend == "heuristic" {
+		if s, ok := utils.SuggestStaged(c.Keys); ok {
+			if s.Type != "" {
+				selector.Select(s.Type)
+			}
+			if s.Scope != "" {
+				scopeSelector.Select(s.Scope)
+			}
+		}
+	}
 
 	m := components.PageView{
 		Page:          components.SELECTION,
 		Selector:      &selector,
 		ScopeSelector: &scopeSelector,
 		Committer:     &committer,
+		Body:          &body,
+		Trailers:      &trailers,
+		Files:         &files,
 		Template:      c.Template,
+		MsgFile:       fs.Arg(0),
+		Options:       opts,
+		Keys:          keys,
+		Help:          help.New(),
+		Branch:        branch,
+		Ticket:        ticket,
+		TicketSuffix:  ticketSuffix,
+		Gitmoji:       c.Gitmoji,
+	}
+	if m.Branch == "" {
+		m.Branch = "HEAD"
+	}
+
+	switch {
+	case opts.Amend:
+		msg := utils.GetCommitMessage("HEAD")
+		if msg == "" {
+			return errors.New("nothing to amend, there are no commits yet")
+		}
+		if c.Gitmoji.Convert {
+			header, rest, _ := strings.Cut(msg, "\n")
+			msg = utils.ConvertGitmoji(header, c.Keys, c.Gitmoji.Format) + "\n" + rest
+		}
+		// the draft of the branch belongs to the next commit, not this one
+		m.Branch = ""
+		m = m.Prefill(utils.MessageDraft(msg))
+	case m.MsgFile == "" && opts.NeedsStaged() && !utils.HasStagedChanges():
+		m.Page = components.FILES
+	}
+	if d, ok := utils.LoadDraft(m.Branch); ok && !opts.Amend {
+		m = m.Resume(d)
 	}
 
 	finalModel, err := tea.NewProgram(m).Run()
 	if err != nil {
-		fmt.Println("Error:", err)
-		os.Exit(1)
+		return err
 	}
 
 	result := finalModel.(components.PageView)
 	if result.FinalMessage == "" {
-		return
+		// cancelled, nothing was committed
+		result.FlushDraft()
+		return exitStatus(1)
+	}
+
+	// with a message file overcommit runs as git's editor, git commits
+	if result.MsgFile != "" {
+		if err := utils.ReplaceHeaderFromCommit(result.FinalMessage, result.MsgFile); err != nil {
+			_ = utils.SaveDraft(result.Draft())
+			return err
+		}
+		_ = utils.ClearDraft(result.Branch)
+		return nil
 	}
 
-	cmd := exec.Command("git", "commit", "-m", result.FinalMessage)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Run()
+	if err := utils.RunCommit(result.FinalMessage, result.Options); err != nil {
+		return commitFailed(err, result)
+	}
+	if result.Branch != "" {
+		_ = utils.ClearDraft(result.Branch)
+	}
+
+	d := result.Draft()
+	_ = utils.AppendHistory(utils.HistoryPath(), utils.HistoryEntry{Repo: utils.GetRepoName(), Type: d.Type, Scope: d.Scope, Subject: d.Subject})
+	return nil
+}
+
+// commitFailed explains why git commit failed and keeps the message, both
+// as a draft for the next run and as a file to retry with git directly.
+func commitFailed(err error, result components.PageView) error {
+	if result.Branch != "" {
+		_ = utils.SaveDraft(result.Draft())
+	}
+
+	reason := fmt.Sprintf("git commit failed with exit status %d", utils.ExitCode(err))
+	var gitErr *utils.GitError
+	if errors.As(err, &gitErr) && !result.Options.NoVerify && !strings.HasPrefix(gitErr.Stderr, "fatal:") {
+		if hooks := utils.CommitHooks(); len(hooks) > 0 {
+			reason = fmt.Sprintf("the commit was rejected by a hook (%s)", strings.Join(hooks, ", "))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\novercommit: %s\n\n", reason)
+	for _, line := range strings.Split(result.FinalMessage, "\n") {
+		fmt.Fprintln(os.Stderr, strings.TrimRight("    "+line, " "))
+	}
+	if result.Branch != "" {
+		fmt.Fprintln(os.Stderr, "\nfix the problem and run overcommit again to resume this message")
+	}
+	if path, saveErr := utils.SaveFailedMessage(result.FinalMessage); saveErr == nil {
+		retry := []string{"git", "commit"}
+		for _, arg := range result.Options.Args() {
+			if strings.ContainsAny(arg, " <>\"'") {
+				arg = strconv.Quote(arg)
+			}
+			retry = append(retry, arg)
+		}
+		fmt.Fprintf(os.Stderr, "\nretry with: %s -e -F %s\n", strings.Join(retry, " "), path)
+	}
+	return exitStatus(utils.ExitCode(err))
 }
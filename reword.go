@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"me.kryptk.overcommit/components"
+	"me.kryptk.overcommit/utils"
+)
+
+func runReword(args []string) error {
+	if len(args) == 3 && args[0] == "--apply-todo" {
+		return utils.ApplyRewordTodo(args[1], args[2])
+	}
+
+	fs := flag.NewFlagSet("reword", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the proposed rewording without changing history")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: overcommit reword [--dry-run] <rev|range>")
+		return errUsage
+	}
+
+	c, err := utils.LoadConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := components.SetTheme(c.Theme); err != nil {
+		return err
+	}
+
+	shas, err := utils.ResolveCommits(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client := utils.NewLLMClient(c.LLM)
+	var rewords []utils.Reword
+	for _, sha := range shas {
+		old := strings.SplitN(utils.GetCommitMessage(sha), "\n", 2)[0]
+		fmt.Fprintf(os.Stderr, "rewording %s %s\n", sha[:7], old)
+
+		prompt, _, err := utils.PrepareRewordPrompt(c.LLM, c.Keys, sha)
+		if err != nil {
+			return err
+		}
+		resp, err := utils.GenerateHeader(client, prompt, c.Lint, c.LLM.Retries)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %v\n", err)
+		}
+		if c.Gitmoji.Convert {
+			resp.Text = utils.ConvertGitmoji(resp.Text, c.Keys, c.Gitmoji.Format)
+		}
+		rewords = append(rewords, utils.Reword{SHA: sha, Old: old, New: resp.Text})
+	}
+
+	if *dryRun {
+		for _, r := range rewords {
+			fmt.Printf("%s %s\n     -> %s\n", r.SHA[:7], r.Old, r.New)
+		}
+		return nil
+	}
+
+	finalModel, err := tea.NewProgram(components.NewRewordView(rewords, c.Lint)).Run()
+	if err != nil {
+		return err
+	}
+
+	result := finalModel.(components.RewordView)
+	if result.Cancelled {
+		return exitStatus(1)
+	}
+	return utils.ApplyRewords(result.Rewords)
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	bulletRe    = regexp.MustCompile(`^(\s*)([-*]|\d+\.)\s+`)
+	paragraphRe = regexp.MustCompile(`\n\s*\n`)
+)
+
+// WrapBody wraps every paragraph and bullet of body to width columns.
+// Bullets get a hanging indent, lines without spaces (URLs) are kept whole.
+func WrapBody(body string, width int) string {
+	if width <= 0 {
+		return body
+	}
+
+	var out []string
+	for _, para := range splitParagraphs(body) {
+		var lines []string
+		for _, item := range splitBullets(para) {
+			indent := ""
+			if m := bulletRe.FindString(item); m != "" {
+				indent = strings.Repeat(" ", len(strings.TrimSpace(m))+1)
+			}
+			lines = append(lines, wrapLine(item, width, indent)...)
+		}
+		out = append(out, strings.Join(lines, "\n"))
+	}
+	return strings.Join(out, "\n\n")
+}
+
+func splitParagraphs(body string) []string {
+	var paras []string
+	for _, p := range paragraphRe.Split(strings.TrimSpace(body), -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			paras = append(paras, p)
+		}
+	}
+	return paras
+}
+
+// splitBullets joins soft-wrapped lines of a paragraph, keeping each bullet
+// as its own item.
+func splitBullets(para string) []string {
+	var items []string
+	for _, line := range strings.Split(para, "\n") {
+		line = strings.TrimRight(line, " ")
+		if len(items) == 0 || bulletRe.MatchString(line) {
+			items = append(items, line)
+			continue
+		}
+		items[len(items)-1] += " " + strings.TrimSpace(line)
+	}
+	return items
+}
+
+func wrapLine(text string, width int, indent string) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var lines []string
+	line := words[0]
+	for _, w := range words[1:] {
+		if len(line)+1+len(w) > width {
+			lines = append(lines, line)
+			line = indent + w
+			continue
+		}
+		line += " " + w
+	}
+	return append(lines, line)
+}
+
+// SanitizeBody cleans model output into a plain commit body wrapped to the
+// configured width.
+func SanitizeBody(text string, l Lint) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if len(lines) == 0 {
+			// drop labels and a repeated subject line before the body starts
+			line = labelRe.ReplaceAllString(trimmed, "")
+			if h, ok := ParseHeader(line); line == "" || ok && slices.Contains(l.Types, h.Type) {
+				continue
+			}
+		}
+		lines = append(lines, emphasisRe.ReplaceAllString(line, ""))
+	}
+
+	return WrapBody(strings.Join(lines, "\n"), l.BodyLineWidth)
+}
+
+// LintBody returns the rule violations of a commit body.
+func LintBody(body string, l Lint) []string {
+	var violations []string
+	for i, line := range strings.Split(body, "\n") {
+		if l.BodyLineWidth > 0 && len(line) > l.BodyLineWidth && strings.Contains(strings.TrimSpace(line), " ") {
+			violations = append(violations, fmt.Sprintf("body line %d exceeds %d chars", i+1, l.BodyLineWidth))
+		}
+	}
+	return violations
+}
+
+// GenerateBody asks client for a commit body and wraps it for review.
+func GenerateBody(client LLMClient, prompt Prompt, l Lint) (Response, error) {
+	resp, err := client.Generate(prompt)
+	if err != nil {
+		return Response{}, err
+	}
+	resp.Text = SanitizeBody(resp.Text, l)
+	if resp.Text == "" {
+		forget(client, prompt)
+		return resp, fmt.Errorf("empty body generated")
+	}
+	return resp, nil
+}
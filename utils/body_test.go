@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestSanitizeBody(t *testing.T) {
+	l := Lint{BodyLineWidth: 72, Types: []string{"feat", "fix"}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"repeated header is dropped", "feat(ui): add x\nAdds x to the list.", "Adds x to the list."},
+		{"label is dropped", "Body:\nAdds x.", "Adds x."},
+		{"prose with a colon is kept", "previously: the cache was shared\nNow it is not.", "previously: the cache was shared Now it is not."},
+		{"fences are dropped", "```\nAdds x.\n```", "Adds x."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeBody(tt.input, l); got != tt.want {
+				t.Errorf("SanitizeBody(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var defaultTicketPatterns = []string{`[A-Z][A-Z0-9]+-\d+`}
+
+var defaultBranchTypes = map[string]string{
+	"feat":     "feat",
+	"feature":  "feat",
+	"fix":      "fix",
+	"bugfix":   "fix",
+	"hotfix":   "fix",
+	"docs":     "docs",
+	"style":    "style",
+	"refactor": "refactor",
+	"test":     "test",
+	"chore":    "chore",
+}
+
+// BranchConfig reads a ticket ID and a commit type from the branch name,
+// as in feature/PROJ-1234-short-desc.
+type BranchConfig struct {
+	// TicketPatterns are tried in order, the first submatch or else the
+	// whole match is the ticket.
+	TicketPatterns []string `json:"ticket_patterns" toml:"ticket_patterns"`
+	// Ticket places the ticket in the message: "trailer", "suffix" or
+	// "none", the default. %t in the templates works regardless.
+	Ticket        string `json:"ticket" toml:"ticket"`
+	TicketTrailer string `json:"ticket_trailer" toml:"ticket_trailer"`
+	// TicketSuffix is appended to the subject, with %t for the ticket.
+	TicketSuffix string `json:"ticket_suffix" toml:"ticket_suffix"`
+	// Types maps the part of the branch name before the first slash to a
+	// commit type, e.g. bugfix = "fix".
+	Types map[string]string `json:"types" toml:"types"`
+}
+
+// TicketFromBranch returns the ticket in branch, or "" without one.
+func TicketFromBranch(branch string, b BranchConfig) (string, error) {
+	for _, pattern := range b.TicketPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid ticket pattern %q: %w", pattern, err)
+		}
+		if m := re.FindStringSubmatch(branch); m != nil {
+			if len(m) > 1 && m[1] != "" {
+				return m[1], nil
+			}
+			return m[0], nil
+		}
+	}
+	return "", nil
+}
+
+// TypeFromBranch returns the commit type for the prefix of branch.
+func TypeFromBranch(branch string, b BranchConfig) (string, bool) {
+	prefix, _, ok := strings.Cut(branch, "/")
+	if !ok {
+		return "", false
+	}
+	typ, ok := b.Types[strings.ToLower(prefix)]
+	return typ, ok
+}
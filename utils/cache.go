@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type CacheConfig struct {
+	Disabled bool          `json:"disabled" toml:"disabled"`
+	TTL      time.Duration `json:"ttl" toml:"ttl"`
+	MaxSize  int64         `json:"max_size" toml:"max_size"`
+}
+
+type cacheEntry struct {
+	Text    string    `json:"text"`
+	Backend string    `json:"backend"`
+	Model   string    `json:"model"`
+	Created time.Time `json:"created"`
+}
+
+// CachedClient serves repeated prompts from disk instead of asking inner.
+// Answers are keyed by the whole backend chain and the prompt templates,
+// so a fallback's answer or one from an older prompt is never served.
+type CachedClient struct {
+	inner   LLMClient
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+	keyBase string
+}
+
+func NewCachedClient(inner LLMClient, cfg LLMConfig) LLMClient {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return inner
+	}
+
+	return &CachedClient{
+		inner:   inner,
+		dir:     filepath.Join(dir, "overcommit", "llm"),
+		ttl:     cfg.Cache.TTL,
+		maxSize: cfg.Cache.MaxSize,
+		keyBase: cacheKeyBase(cfg),
+	}
+}
+
+func cacheKeyBase(cfg LLMConfig) string {
+	parts := []string{templateHash(cfg.Prompt)}
+	for _, b := range append([]LLMBackend{cfg.Primary()}, cfg.Fallback...) {
+		parts = append(parts, b.Backend, b.Model, b.BaseURL)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// templateHash identifies the built-in and configured prompt templates,
+// changing either invalidates the cached generations.
+func templateHash(cfg PromptConfig) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		defaultSystemPrompt, defaultUserPrompt, defaultBodyPrompt, defaultRewordPrompt,
+		cfg.System, cfg.User, cfg.Body, cfg.Reword,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *CachedClient) key(prompt Prompt) string {
+	sum := sha256.Sum256([]byte(c.keyBase + "\x00" + PromptHash(prompt)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *CachedClient) path(prompt Prompt) string {
+	return filepath.Join(c.dir, c.key(prompt)+".json")
+}
+
+func (c *CachedClient) Generate(prompt Prompt) (Response, error) {
+	path := c.path(prompt)
+
+	if data, err := os.ReadFile(path); err == nil {
+		var e cacheEntry
+		if json.Unmarshal(data, &e) == nil && (c.ttl <= 0 || time.Since(e.Created) < c.ttl) {
+			return Response{Text: e.Text, Backend: e.Backend, Model: e.Model, Cached: true}, nil
+		}
+	}
+
+	resp, err := c.inner.Generate(prompt)
+	if err != nil {
+		return resp, err
+	}
+
+	data, _ := json.Marshal(cacheEntry{Text: resp.Text, Backend: resp.Backend, Model: resp.Model, Created: time.Now()})
+	if os.MkdirAll(c.dir, 0755) == nil && os.WriteFile(path, data, 0644) == nil {
+		c.prune()
+	}
+	return resp, nil
+}
+
+// Forget drops the answer to prompt again, for answers that failed lint.
+func (c *CachedClient) Forget(prompt Prompt) {
+	os.Remove(c.path(prompt))
+}
+
+// forget drops the answer to prompt from the cache of client, if any.
+func forget(client LLMClient, prompt Prompt) {
+	if c, ok := client.(*CachedClient); ok {
+		c.Forget(prompt)
+	}
+}
+
+// prune drops expired entries and then the oldest ones until the cache
+// fits in maxSize bytes.
+func (c *CachedClient) prune() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	var files []os.FileInfo
+	var total int64
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if c.ttl > 0 && time.Since(info.ModTime()) > c.ttl {
+			os.Remove(filepath.Join(c.dir, e.Name()))
+			continue
+		}
+		files = append(files, info)
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, f := range files {
+		if c.maxSize <= 0 || total <= c.maxSize {
+			break
+		}
+		os.Remove(filepath.Join(c.dir, f.Name()))
+		total -= f.Size()
+	}
+}
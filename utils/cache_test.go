@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+)
+
+type countingClient struct {
+	calls int
+	text  string
+}
+
+func (c *countingClient) Generate(Prompt) (Response, error) {
+	c.calls++
+	return Response{Text: fmt.Sprintf("%s %d", c.text, c.calls)}, nil
+}
+
+func TestCacheKey(t *testing.T) {
+	base := LLMConfig{Backend: "ollama", Model: "tinyllama"}
+
+	tests := []struct {
+		name string
+		cfg  func(LLMConfig) LLMConfig
+	}{
+		{"fallback", func(c LLMConfig) LLMConfig {
+			c.Fallback = []LLMBackend{{Backend: "anthropic", Model: "m"}}
+			return c
+		}},
+		{"model", func(c LLMConfig) LLMConfig { c.Model = "other"; return c }},
+		{"base url", func(c LLMConfig) LLMConfig { c.BaseURL = "http://x"; return c }},
+		{"prompt template", func(c LLMConfig) LLMConfig { c.Prompt.User = "{{.Diff}}"; return c }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cacheKeyBase(tt.cfg(base)) == cacheKeyBase(base) {
+				t.Error("cache key did not change")
+			}
+		})
+	}
+}
+
+func TestCachedClient(t *testing.T) {
+	inner := &countingClient{text: "Added the thing."}
+	client := &CachedClient{inner: inner, dir: t.TempDir(), keyBase: cacheKeyBase(LLMConfig{})}
+	prompt := Prompt{User: "u"}
+
+	a, _ := client.Generate(prompt)
+	b, _ := client.Generate(prompt)
+	if inner.calls != 1 || !b.Cached || a.Text != b.Text {
+		t.Fatalf("second generation was not cached: %d calls, %+v", inner.calls, b)
+	}
+
+	// a subject failing lint is not served again
+	l := Lint{MaxSubjectLength: 5, SubjectCase: "any"}
+	if _, err := GenerateSubject(client, prompt, l, 0); err != nil {
+		t.Fatal(err)
+	}
+	client.Generate(prompt)
+	if inner.calls != 2 {
+		t.Errorf("answer failing lint was cached, %d calls", inner.calls)
+	}
+}
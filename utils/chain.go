@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type LLMBackend struct {
+	Backend string `json:"backend" toml:"backend"`
+	Model   string `json:"model" toml:"model"`
+	BaseURL string `json:"base_url" toml:"base_url"`
+}
+
+type RetryConfig struct {
+	Attempts  int           `json:"attempts" toml:"attempts"`
+	BaseDelay time.Duration `json:"base_delay" toml:"base_delay"`
+	MaxDelay  time.Duration `json:"max_delay" toml:"max_delay"`
+}
+
+// Primary is the backend configured at the top level of [llm].
+func (c LLMConfig) Primary() LLMBackend {
+	return LLMBackend{Backend: c.Backend, Model: c.Model, BaseURL: c.BaseURL}
+}
+
+// IsRemote reports whether any backend in the chain leaves this machine.
+func (c LLMConfig) IsRemote() bool {
+	if IsRemoteBackend(c.Backend) {
+		return true
+	}
+	for _, b := range c.Fallback {
+		if IsRemoteBackend(b.Backend) {
+			return true
+		}
+	}
+	return false
+}
+
+// APIError is a non-200 answer from a backend.
+type APIError struct {
+	Backend    string
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s error: %s", e.Backend, e.Body)
+}
+
+func newAPIError(backend string, resp *http.Response) error {
+	b, _ := io.ReadAll(resp.Body)
+	return &APIError{
+		Backend:    backend,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Body:       string(b),
+	}
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// IsTransient reports whether err is worth retrying: rate limits, server
+// errors and network failures.
+func IsTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ChainClient tries its clients in order, retrying transient errors with
+// exponential backoff before falling through to the next one.
+type ChainClient struct {
+	clients []LLMClient
+	retry   RetryConfig
+	sleep   func(time.Duration)
+}
+
+func NewChainClient(clients []LLMClient, retry RetryConfig) *ChainClient {
+	return &ChainClient{clients: clients, retry: retry, sleep: time.Sleep}
+}
+
+func (c *ChainClient) Generate(prompt Prompt) (Response, error) {
+	var errs []error
+
+	for _, client := range c.clients {
+		for attempt := 0; ; attempt++ {
+			resp, err := client.Generate(prompt)
+			if err == nil {
+				return resp, nil
+			}
+			delay := c.backoff(err, attempt)
+			if !IsTransient(err) || attempt+1 >= c.retry.Attempts || (c.retry.MaxDelay > 0 && delay > c.retry.MaxDelay) {
+				errs = append(errs, err)
+				break
+			}
+			c.sleep(delay)
+		}
+	}
+
+	if len(errs) == 1 {
+		return Response{}, errs[0]
+	}
+	return Response{}, fmt.Errorf("all backends failed: %w", errors.Join(errs...))
+}
+
+func (c *ChainClient) backoff(err error, attempt int) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+
+	delay := c.retry.BaseDelay << attempt
+	if c.retry.MaxDelay > 0 && delay > c.retry.MaxDelay {
+		delay = c.retry.MaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChainClient(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "test")
+
+	flaky := func(failures int, status int, retryAfter string) (*httptest.Server, *int) {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= failures {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(status)
+				return
+			}
+			w.Write([]byte(`{"response": "add from ollama"}`))
+		}))
+		return srv, &calls
+	}
+
+	anthropic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content": [{"text": "add from anthropic"}]}`))
+	}))
+	defer anthropic.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	retry := RetryConfig{Attempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		name        string
+		failures    int
+		status      int
+		retryAfter  string
+		primaryDown bool
+		wantBackend string
+		wantCalls   int
+		wantSleeps  []time.Duration
+	}{
+		{"first try", 0, 0, "", false, "ollama", 1, nil},
+		{"retries 5xx with backoff", 2, 503, "", false, "ollama", 3, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+		{"honors retry-after", 1, 429, "2", false, "ollama", 2, []time.Duration{2 * time.Second}},
+		{"retry-after beyond max delay falls back", 1, 429, "60", false, "anthropic", 1, nil},
+		{"exhausted retries fall back", 3, 500, "", false, "anthropic", 3, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+		{"client error falls back without retry", 1, 400, "", false, "anthropic", 1, nil},
+		{"unreachable backend falls back", 0, 0, "", true, "anthropic", 0, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := flaky(tt.failures, tt.status, tt.retryAfter)
+			defer srv.Close()
+
+			url := srv.URL
+			if tt.primaryDown {
+				url = down.URL
+			}
+
+			var sleeps []time.Duration
+			chain := NewChainClient([]LLMClient{
+				&OllamaClient{model: "test", baseURL: url, http: http.DefaultClient},
+				&AnthropicClient{model: "test", baseURL: anthropic.URL, http: http.DefaultClient},
+			}, retry)
+			chain.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			resp, err := chain.Generate(Prompt{User: "u"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Backend != tt.wantBackend || !strings.Contains(resp.Text, tt.wantBackend) {
+				t.Fatalf("answered by %q (%q), want %q", resp.Backend, resp.Text, tt.wantBackend)
+			}
+			if *calls != tt.wantCalls {
+				t.Fatalf("primary called %d times, want %d", *calls, tt.wantCalls)
+			}
+			if len(sleeps) != len(tt.wantSleeps) {
+				t.Fatalf("sleeps = %v, want %v", sleeps, tt.wantSleeps)
+			}
+			for i := range sleeps {
+				if sleeps[i] != tt.wantSleeps[i] {
+					t.Fatalf("sleeps = %v, want %v", sleeps, tt.wantSleeps)
+				}
+			}
+		})
+	}
+}
+
+func TestChainClientAllFail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte("boom"))
+	}))
+	defer srv.Close()
+
+	chain := NewChainClient([]LLMClient{
+		&OllamaClient{baseURL: srv.URL, http: http.DefaultClient},
+		&OpenAIClient{baseURL: srv.URL, http: http.DefaultClient},
+	}, RetryConfig{Attempts: 1})
+	t.Setenv("OPENAI_API_KEY", "")
+
+	_, err := chain.Generate(Prompt{User: "u"})
+	if err == nil || !strings.Contains(err.Error(), "ollama error: boom") || !strings.Contains(err.Error(), "OPENAI_API_KEY not set") {
+		t.Fatalf("err = %v", err)
+	}
+}
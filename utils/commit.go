@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"strings"
+)
+
+// CommitOptions are passed through to git commit. All but Amend can be
+// defaulted in the [commit] table of the config.
+type CommitOptions struct {
+	Amend      bool   `json:"-" toml:"-"`
+	Sign       bool   `json:"sign" toml:"sign"`
+	SigningKey string `json:"signing_key" toml:"signing_key"`
+	Signoff    bool   `json:"signoff" toml:"signoff"`
+	NoVerify   bool   `json:"no_verify" toml:"no_verify"`
+	Author     string `json:"author" toml:"author"`
+	Date       string `json:"date" toml:"date"`
+	AllowEmpty bool   `json:"allow_empty" toml:"allow_empty"`
+	All        bool   `json:"all" toml:"all"`
+}
+
+// Args are the git commit arguments for o, without the message.
+func (o CommitOptions) Args() []string {
+	var args []string
+	if o.Amend {
+		args = append(args, "--amend")
+	}
+	if o.Sign || o.SigningKey != "" {
+		args = append(args, "-S"+o.SigningKey)
+	}
+	if o.Signoff {
+		args = append(args, "--signoff")
+	}
+	if o.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	if o.Author != "" {
+		args = append(args, "--author="+o.Author)
+	}
+	if o.Date != "" {
+		args = append(args, "--date="+o.Date)
+	}
+	if o.AllowEmpty {
+		args = append(args, "--allow-empty")
+	}
+	if o.All {
+		args = append(args, "--all")
+	}
+	return args
+}
+
+// Active names the options in effect, for showing them while the message
+// is written.
+func (o CommitOptions) Active() []string {
+	var active []string
+	if o.Amend {
+		active = append(active, "amend")
+	}
+	if o.Sign || o.SigningKey != "" {
+		active = append(active, "signed")
+	}
+	if o.Signoff {
+		active = append(active, "signoff")
+	}
+	if o.NoVerify {
+		active = append(active, "no-verify")
+	}
+	if o.Author != "" {
+		active = append(active, "author "+o.Author)
+	}
+	if o.Date != "" {
+		active = append(active, "date "+o.Date)
+	}
+	if o.AllowEmpty {
+		active = append(active, "allow-empty")
+	}
+	if o.All {
+		active = append(active, "all")
+	}
+	return active
+}
+
+// NeedsStaged reports whether git commit fails with nothing staged.
+func (o CommitOptions) NeedsStaged() bool {
+	return !o.Amend && !o.AllowEmpty && !o.All
+}
+
+// RunCommit runs git commit with the message on stdin, so that it is
+// taken as is however many lines it has.
+func RunCommit(msg string, o CommitOptions) error {
+	args := append([]string{"commit"}, o.Args()...)
+	return GitPassthrough(strings.NewReader(msg), append(args, "-F", "-")...)
+}
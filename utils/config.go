@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -11,15 +12,67 @@ type Config struct {
 	Keys     []Key     `json:"keys" toml:"keys"`
 	Lint     Lint      `json:"lint" toml:"lint"`
 	LLM      LLMConfig `json:"llm" toml:"llm"`
+
+	Keybindings Keybindings    `json:"keybindings" toml:"keybindings"`
+	Theme       ThemeConfig    `json:"theme" toml:"theme"`
+	Commit      CommitOptions  `json:"commit" toml:"commit"`
+	Trailers    TrailersConfig `json:"trailers" toml:"trailers"`
+	Branch      BranchConfig   `json:"branch" toml:"branch"`
+	Gitmoji     GitmojiConfig  `json:"gitmoji" toml:"gitmoji"`
+}
+
+// ThemeConfig selects a built-in theme ("auto", "dark", "light" or
+// "high-contrast") and color profile ("auto", "truecolor", "ansi256",
+// "ansi" or "ascii"). Single colors can be overridden, as can the chroma
+// style diffs are highlighted with ("none" turns highlighting off).
+type ThemeConfig struct {
+	Name    string `json:"name" toml:"name"`
+	Profile string `json:"profile" toml:"profile"`
+	Accent  string `json:"accent" toml:"accent"`
+	Error   string `json:"error" toml:"error"`
+	Success string `json:"success" toml:"success"`
+	Syntax  string `json:"syntax" toml:"syntax"`
+}
+
+// Keybindings picks a preset ("default", "vim" or "emacs") and remaps
+// single actions, e.g. generate = ["ctrl+g", "alt+g"].
+type Keybindings struct {
+	Preset string              `json:"preset" toml:"preset"`
+	Keys   map[string][]string `json:"keys" toml:"keys"`
 }
 
 type Lint struct {
-	MaxSubjectLength int `json:"max_subject_length" toml:"max_subject_length"`
+	MaxSubjectLength    int    `json:"max_subject_length" toml:"max_subject_length"`
+	SubjectCase         string `json:"subject_case" toml:"subject_case"`
+	AllowTrailingPeriod bool   `json:"allow_trailing_period" toml:"allow_trailing_period"`
+	BodyLineWidth       int    `json:"body_line_width" toml:"body_line_width"`
+	// RequireTicket lists the types whose messages must reference a
+	// ticket matching TicketPattern.
+	RequireTicket []string `json:"require_ticket" toml:"require_ticket"`
+	TicketPattern string   `json:"ticket_pattern" toml:"ticket_pattern"`
+	// Types are the prefixes of the configured keys, filled in by LoadConfig.
+	Types []string `json:"-" toml:"-"`
 }
 
 type LLMConfig struct {
-	Backend string `json:"backend" toml:"backend"`
-	Model   string `json:"model" toml:"model"`
+	Backend  string `json:"backend" toml:"backend"`
+	Model    string `json:"model" toml:"model"`
+	BaseURL  string `json:"base_url" toml:"base_url"`
+	Fixtures string `json:"fixtures" toml:"fixtures"`
+	Cassette string `json:"cassette" toml:"cassette"`
+	Record   bool   `json:"record" toml:"record"`
+	// Retries is how often the model is re-asked when its answer fails
+	// lint, -1 disables re-asking.
+	Retries int `json:"retries" toml:"retries"`
+	// Fallback backends are tried in order when Backend fails.
+	Fallback []LLMBackend `json:"fallback" toml:"fallback"`
+
+	Retry  RetryConfig  `json:"retry" toml:"retry"`
+	Diff   DiffConfig   `json:"diff" toml:"diff"`
+	Redact RedactConfig `json:"redact" toml:"redact"`
+	Prompt PromptConfig `json:"prompt" toml:"prompt"`
+	Cache  CacheConfig  `json:"cache" toml:"cache"`
+	Usage  UsageConfig  `json:"usage" toml:"usage"`
 }
 
 type Template struct {
@@ -30,6 +83,10 @@ type Template struct {
 type Key struct {
 	Prefix      string `json:"prefix" toml:"prefix"`
 	Description string `json:"description" toml:"description"`
+	// Emoji and Shortcode are the gitmoji of the type, e.g. "✨" and
+	// "sparkles", written into messages through %e.
+	Emoji     string `json:"emoji" toml:"emoji"`
+	Shortcode string `json:"shortcode" toml:"shortcode"`
 }
 
 func (k Key) FilterValue() string {
@@ -57,28 +114,101 @@ func LoadConfig(embeddedConfig string) (Config, error) {
 	setDefaults(&cfg)
 
 	repoConfigPath := os.ExpandEnv("$PWD/.overcommit.toml")
-	if _, err := os.Stat(repoConfigPath); os.IsNotExist(err) {
-		return cfg, nil
+	if _, err := os.Stat(repoConfigPath); err == nil {
+		if repoCfg, err := GenerateConfigFromFile(repoConfigPath); err == nil {
+			cfg = mergeConfigs(cfg, repoCfg)
+		}
 	}
 
-	repoCfg, err := GenerateConfigFromFile(repoConfigPath)
-	if err != nil {
-		return cfg, nil
+	// the preset works on the merged keys, so repositories can turn it on
+	if cfg.Gitmoji.Preset {
+		applyGitmojiPreset(&cfg)
 	}
-
-	return mergeConfigs(cfg, repoCfg), nil
+	for _, k := range cfg.Keys {
+		cfg.Lint.Types = append(cfg.Lint.Types, k.Prefix)
+	}
+	return cfg, nil
 }
 
 func setDefaults(cfg *Config) {
 	if cfg.Lint.MaxSubjectLength == 0 {
 		cfg.Lint.MaxSubjectLength = 50
 	}
+	if cfg.Lint.BodyLineWidth == 0 {
+		cfg.Lint.BodyLineWidth = 72
+	}
+	if cfg.Lint.SubjectCase == "" {
+		cfg.Lint.SubjectCase = "any"
+	}
+	if cfg.LLM.Cache.TTL == 0 {
+		cfg.LLM.Cache.TTL = 7 * 24 * time.Hour
+	}
+	if cfg.LLM.Cache.MaxSize == 0 {
+		cfg.LLM.Cache.MaxSize = 5 << 20
+	}
+	if cfg.LLM.Usage.Prices == nil {
+		cfg.LLM.Usage.Prices = make(map[string]Price)
+		for model, price := range defaultPrices {
+			cfg.LLM.Usage.Prices[model] = price
+		}
+	}
+	if cfg.LLM.Retry.Attempts == 0 {
+		cfg.LLM.Retry.Attempts = 3
+	}
+	if cfg.LLM.Retry.BaseDelay == 0 {
+		cfg.LLM.Retry.BaseDelay = 500 * time.Millisecond
+	}
+	if cfg.LLM.Retry.MaxDelay == 0 {
+		cfg.LLM.Retry.MaxDelay = 10 * time.Second
+	}
+	if cfg.LLM.Retries == 0 {
+		cfg.LLM.Retries = 2
+	}
 	if cfg.LLM.Backend == "" {
 		cfg.LLM.Backend = "ollama"
 	}
 	if cfg.LLM.Model == "" {
 		cfg.LLM.Model = "tinyllama"
 	}
+	if cfg.LLM.Diff.MaxTokens == 0 {
+		cfg.LLM.Diff.MaxTokens = 2000
+	}
+	if cfg.LLM.Diff.Exclude == nil {
+		cfg.LLM.Diff.Exclude = defaultDiffExclude
+	}
+	if cfg.LLM.Redact.Policy == "" {
+		cfg.LLM.Redact.Policy = "redact"
+	}
+	if cfg.LLM.Redact.DenyPaths == nil {
+		cfg.LLM.Redact.DenyPaths = defaultDenyPaths
+	}
+	if cfg.LLM.Redact.Entropy == 0 {
+		cfg.LLM.Redact.Entropy = 4.5
+	}
+	if cfg.Lint.TicketPattern == "" {
+		cfg.Lint.TicketPattern = `[A-Z][A-Z0-9]+-\d+|#\d+`
+	}
+	if cfg.Branch.TicketPatterns == nil {
+		cfg.Branch.TicketPatterns = defaultTicketPatterns
+	}
+	if cfg.Branch.Ticket == "" {
+		cfg.Branch.Ticket = "none"
+	}
+	if cfg.Branch.TicketTrailer == "" {
+		cfg.Branch.TicketTrailer = "Refs"
+	}
+	if cfg.Branch.TicketSuffix == "" {
+		cfg.Branch.TicketSuffix = " (%t)"
+	}
+	if cfg.Branch.Types == nil {
+		cfg.Branch.Types = defaultBranchTypes
+	}
+	if cfg.Gitmoji.Format == "" {
+		cfg.Gitmoji.Format = "unicode"
+	}
+	if cfg.Trailers.Keys == nil {
+		cfg.Trailers.Keys = defaultTrailerKeys
+	}
 }
 
 func mergeConfigs(base, repo Config) Config {
@@ -94,11 +224,190 @@ func mergeConfigs(base, repo Config) Config {
 	if repo.Lint.MaxSubjectLength > 0 {
 		base.Lint.MaxSubjectLength = repo.Lint.MaxSubjectLength
 	}
+	if repo.Lint.BodyLineWidth > 0 {
+		base.Lint.BodyLineWidth = repo.Lint.BodyLineWidth
+	}
+	if repo.Lint.SubjectCase != "" {
+		base.Lint.SubjectCase = repo.Lint.SubjectCase
+	}
+	if repo.Lint.RequireTicket != nil {
+		base.Lint.RequireTicket = repo.Lint.RequireTicket
+	}
+	if repo.Lint.TicketPattern != "" {
+		base.Lint.TicketPattern = repo.Lint.TicketPattern
+	}
+	if repo.Lint.AllowTrailingPeriod {
+		base.Lint.AllowTrailingPeriod = true
+	}
+	if repo.LLM.Cache.Disabled {
+		base.LLM.Cache.Disabled = true
+	}
+	if repo.LLM.Cache.TTL > 0 {
+		base.LLM.Cache.TTL = repo.LLM.Cache.TTL
+	}
+	if repo.LLM.Cache.MaxSize > 0 {
+		base.LLM.Cache.MaxSize = repo.LLM.Cache.MaxSize
+	}
+	if repo.LLM.Fallback != nil {
+		base.LLM.Fallback = repo.LLM.Fallback
+	}
+	if repo.LLM.Retry.Attempts > 0 {
+		base.LLM.Retry.Attempts = repo.LLM.Retry.Attempts
+	}
+	if repo.LLM.Retry.BaseDelay > 0 {
+		base.LLM.Retry.BaseDelay = repo.LLM.Retry.BaseDelay
+	}
+	if repo.LLM.Retry.MaxDelay > 0 {
+		base.LLM.Retry.MaxDelay = repo.LLM.Retry.MaxDelay
+	}
+	if repo.LLM.Usage.Disabled {
+		base.LLM.Usage.Disabled = true
+	}
+	if repo.LLM.Usage.Ledger != "" {
+		base.LLM.Usage.Ledger = repo.LLM.Usage.Ledger
+	}
+	for model, price := range repo.LLM.Usage.Prices {
+		base.LLM.Usage.Prices[model] = price
+	}
+	if repo.LLM.BaseURL != "" {
+		base.LLM.BaseURL = repo.LLM.BaseURL
+	}
+	if repo.LLM.Fixtures != "" {
+		base.LLM.Fixtures = repo.LLM.Fixtures
+	}
+	if repo.LLM.Cassette != "" {
+		base.LLM.Cassette = repo.LLM.Cassette
+	}
+	if repo.LLM.Record {
+		base.LLM.Record = true
+	}
+	if repo.LLM.Retries != 0 {
+		base.LLM.Retries = repo.LLM.Retries
+	}
 	if repo.LLM.Backend != "" {
 		base.LLM.Backend = repo.LLM.Backend
 	}
 	if repo.LLM.Model != "" {
 		base.LLM.Model = repo.LLM.Model
 	}
+	if repo.LLM.Diff.MaxTokens > 0 {
+		base.LLM.Diff.MaxTokens = repo.LLM.Diff.MaxTokens
+	}
+	if repo.LLM.Diff.Exclude != nil {
+		base.LLM.Diff.Exclude = repo.LLM.Diff.Exclude
+	}
+	if repo.LLM.Redact.Policy != "" {
+		base.LLM.Redact.Policy = repo.LLM.Redact.Policy
+	}
+	if repo.LLM.Redact.SkipLocal {
+		base.LLM.Redact.SkipLocal = true
+	}
+	if repo.LLM.Redact.Entropy > 0 {
+		base.LLM.Redact.Entropy = repo.LLM.Redact.Entropy
+	}
+	if repo.LLM.Redact.DenyPaths != nil {
+		base.LLM.Redact.DenyPaths = repo.LLM.Redact.DenyPaths
+	}
+	if repo.LLM.Prompt.System != "" {
+		base.LLM.Prompt.System = repo.LLM.Prompt.System
+	}
+	if repo.LLM.Prompt.User != "" {
+		base.LLM.Prompt.User = repo.LLM.Prompt.User
+	}
+	if repo.LLM.Prompt.Body != "" {
+		base.LLM.Prompt.Body = repo.LLM.Prompt.Body
+	}
+	if repo.LLM.Prompt.Reword != "" {
+		base.LLM.Prompt.Reword = repo.LLM.Prompt.Reword
+	}
+	if repo.LLM.Prompt.Examples > 0 {
+		base.LLM.Prompt.Examples = repo.LLM.Prompt.Examples
+	}
+	if repo.Theme.Name != "" {
+		base.Theme.Name = repo.Theme.Name
+	}
+	if repo.Theme.Profile != "" {
+		base.Theme.Profile = repo.Theme.Profile
+	}
+	if repo.Theme.Accent != "" {
+		base.Theme.Accent = repo.Theme.Accent
+	}
+	if repo.Theme.Error != "" {
+		base.Theme.Error = repo.Theme.Error
+	}
+	if repo.Theme.Success != "" {
+		base.Theme.Success = repo.Theme.Success
+	}
+	if repo.Theme.Syntax != "" {
+		base.Theme.Syntax = repo.Theme.Syntax
+	}
+	if repo.Commit.Sign {
+		base.Commit.Sign = true
+	}
+	if repo.Commit.SigningKey != "" {
+		base.Commit.SigningKey = repo.Commit.SigningKey
+	}
+	if repo.Commit.Signoff {
+		base.Commit.Signoff = true
+	}
+	if repo.Commit.NoVerify {
+		base.Commit.NoVerify = true
+	}
+	if repo.Commit.Author != "" {
+		base.Commit.Author = repo.Commit.Author
+	}
+	if repo.Commit.Date != "" {
+		base.Commit.Date = repo.Commit.Date
+	}
+	if repo.Commit.AllowEmpty {
+		base.Commit.AllowEmpty = true
+	}
+	if repo.Commit.All {
+		base.Commit.All = true
+	}
+	if repo.Branch.TicketPatterns != nil {
+		base.Branch.TicketPatterns = repo.Branch.TicketPatterns
+	}
+	if repo.Branch.Ticket != "" {
+		base.Branch.Ticket = repo.Branch.Ticket
+	}
+	if repo.Branch.TicketTrailer != "" {
+		base.Branch.TicketTrailer = repo.Branch.TicketTrailer
+	}
+	if repo.Branch.TicketSuffix != "" {
+		base.Branch.TicketSuffix = repo.Branch.TicketSuffix
+	}
+	if repo.Branch.Types != nil {
+		base.Branch.Types = repo.Branch.Types
+	}
+	if repo.Gitmoji.Preset {
+		base.Gitmoji.Preset = true
+	}
+	if repo.Gitmoji.Format != "" {
+		base.Gitmoji.Format = repo.Gitmoji.Format
+	}
+	if repo.Gitmoji.Convert {
+		base.Gitmoji.Convert = true
+	}
+	if repo.Trailers.Keys != nil {
+		base.Trailers.Keys = repo.Trailers.Keys
+	}
+	base.Trailers.Roster = append(base.Trailers.Roster, repo.Trailers.Roster...)
+	if repo.Keybindings.Preset != "" {
+		base.Keybindings.Preset = repo.Keybindings.Preset
+	}
+	for action, keys := range repo.Keybindings.Keys {
+		if base.Keybindings.Keys == nil {
+			base.Keybindings.Keys = make(map[string][]string)
+		}
+		base.Keybindings.Keys[action] = keys
+	}
+	base.LLM.Redact.Patterns = append(base.LLM.Redact.Patterns, repo.LLM.Redact.Patterns...)
+	for model, budget := range repo.LLM.Diff.Budgets {
+		if base.LLM.Diff.Budgets == nil {
+			base.LLM.Diff.Budgets = make(map[string]int)
+		}
+		base.LLM.Diff.Budgets[model] = budget
+	}
 	return base
 }
@@ -0,0 +1,337 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type DiffConfig struct {
+	MaxTokens int            `json:"max_tokens" toml:"max_tokens"`
+	Budgets   map[string]int `json:"budgets" toml:"budgets"`
+	Exclude   []string       `json:"exclude" toml:"exclude"`
+}
+
+var defaultDiffExclude = []string{
+	"*.lock", "go.sum", "package-lock.json", "pnpm-lock.yaml", "yarn.lock",
+	"*.min.js", "*.min.css", "*.map", "*.pb.go", "*_generated.go", "*.gen.go",
+	"vendor/**", "node_modules/**", "dist/**",
+}
+
+// Budget returns the token budget for the diff part of a prompt sent to model.
+func (c DiffConfig) Budget(model string) int {
+	if b, ok := c.Budgets[model]; ok && b > 0 {
+		return b
+	}
+	return c.MaxTokens
+}
+
+type FileDiff struct {
+	Path    string
+	OldPath string
+	Status  string
+	Binary  bool
+	Header  []string
+	Hunks   []Hunk
+	Added   int
+	Deleted int
+}
+
+type Hunk struct {
+	Header  string
+	Lines   []string
+	Added   int
+	Deleted int
+}
+
+func (h Hunk) String() string {
+	return h.Header + "\n" + strings.Join(h.Lines, "\n") + "\n"
+}
+
+// ParseDiff splits a unified git diff into per-file hunks.
+// Input that is not in `git diff` format yields no files.
+func ParseDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var file *FileDiff
+	var hunk *Hunk
+
+	flushHunk := func() {
+		if file != nil && hunk != nil {
+			file.Hunks = append(file.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if file != nil {
+			files = append(files, *file)
+		}
+		file = nil
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flushFile()
+			file = &FileDiff{Status: "modified", Header: []string{line}}
+			if i := strings.Index(line, " b/"); i >= 0 {
+				file.Path = line[i+3:]
+				file.OldPath = strings.TrimPrefix(line[len("diff --git "):i], "a/")
+			}
+			continue
+		}
+		if file == nil {
+			continue
+		}
+
+		if hunk != nil {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+			case strings.HasPrefix(line, "+"):
+				hunk.Added++
+				file.Added++
+				hunk.Lines = append(hunk.Lines, line)
+				continue
+			case strings.HasPrefix(line, "-"):
+				hunk.Deleted++
+				file.Deleted++
+				hunk.Lines = append(hunk.Lines, line)
+				continue
+			case strings.HasPrefix(line, " "), strings.HasPrefix(line, `\`):
+				hunk.Lines = append(hunk.Lines, line)
+				continue
+			default:
+				flushHunk()
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunk = &Hunk{Header: line}
+		case strings.HasPrefix(line, "new file mode"):
+			file.Status = "added"
+			file.Header = append(file.Header, line)
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.Status = "deleted"
+			file.Header = append(file.Header, line)
+		case strings.HasPrefix(line, "rename from "):
+			file.Status = "renamed"
+			file.OldPath = strings.TrimPrefix(line, "rename from ")
+			file.Header = append(file.Header, line)
+		case strings.HasPrefix(line, "rename to "):
+			file.Path = strings.TrimPrefix(line, "rename to ")
+			file.Header = append(file.Header, line)
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			file.Binary = true
+			file.Header = append(file.Header, line)
+		case strings.HasPrefix(line, "+++ "):
+			if p := strings.TrimPrefix(line, "+++ "); p != "/dev/null" {
+				file.Path = strings.TrimPrefix(p, "b/")
+			}
+			file.Header = append(file.Header, line)
+		case strings.HasPrefix(line, "--- "):
+			if p := strings.TrimPrefix(line, "--- "); p != "/dev/null" {
+				file.OldPath = strings.TrimPrefix(p, "a/")
+			}
+			file.Header = append(file.Header, line)
+		case line != "":
+			file.Header = append(file.Header, line)
+		}
+	}
+	flushFile()
+
+	return files
+}
+
+// DiffStat renders a `git diff --stat` like overview of files.
+func DiffStat(files []FileDiff, excluded func(FileDiff) bool) string {
+	var b strings.Builder
+	var added, deleted int
+	for _, f := range files {
+		name := f.Path
+		if f.Status == "renamed" && f.OldPath != f.Path {
+			name = fmt.Sprintf("%s => %s", f.OldPath, f.Path)
+		}
+
+		note := ""
+		switch {
+		case f.Binary:
+			note = " (binary)"
+		case excluded != nil && excluded(f):
+			note = " (excluded)"
+		}
+		if f.Status != "modified" {
+			note = fmt.Sprintf(" [%s]%s", f.Status, note)
+		}
+
+		fmt.Fprintf(&b, " %s | +%d -%d%s\n", name, f.Added, f.Deleted, note)
+		added += f.Added
+		deleted += f.Deleted
+	}
+	fmt.Fprintf(&b, " %d files changed, %d insertions(+), %d deletions(-)\n", len(files), added, deleted)
+	return b.String()
+}
+
+// CondenseDiff reduces a staged diff to fit the token budget of model, a
+// budget of 0 meaning no limit. It always keeps a stat overview, drops
+// excluded and binary files, and takes hunks round-robin across files so
+// that one large file cannot crowd out the rest.
+func CondenseDiff(diff string, cfg DiffConfig, model string) string {
+	budget := cfg.Budget(model)
+	files := ParseDiff(diff)
+	if len(files) == 0 {
+		return TruncateTokens(diff, budget)
+	}
+
+	excluded := func(f FileDiff) bool {
+		return f.Binary || MatchAnyGlob(cfg.Exclude, f.Path)
+	}
+
+	stat := DiffStat(files, excluded)
+	// without a budget everything fits, excluded files are still dropped
+	remaining := math.MaxInt
+	if budget > 0 {
+		remaining = budget - EstimateTokens(stat)
+	}
+
+	var included []int
+	for i, f := range files {
+		if !excluded(f) {
+			included = append(included, i)
+		}
+	}
+	sort.SliceStable(included, func(a, b int) bool {
+		fa, fb := files[included[a]], files[included[b]]
+		return fa.Added+fa.Deleted > fb.Added+fb.Deleted
+	})
+
+	picked := make(map[int][]bool)
+	headerCost := make(map[int]int)
+	for _, i := range included {
+		picked[i] = make([]bool, len(files[i].Hunks))
+		headerCost[i] = EstimateTokens(strings.Join(files[i].Header, "\n"))
+	}
+
+	omitted := 0
+	used := make(map[int]bool)
+	for round := 0; ; round++ {
+		more := false
+		for _, i := range included {
+			f := files[i]
+			if round == 0 && len(f.Hunks) == 0 {
+				if headerCost[i] <= remaining {
+					remaining -= headerCost[i]
+					used[i] = true
+				}
+				continue
+			}
+			if round >= len(f.Hunks) {
+				continue
+			}
+			more = true
+
+			cost := EstimateTokens(f.Hunks[round].String())
+			if !used[i] {
+				cost += headerCost[i]
+			}
+			if cost > remaining {
+				omitted++
+				continue
+			}
+			remaining -= cost
+			used[i] = true
+			picked[i][round] = true
+		}
+		if !more {
+			break
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Files changed:\n")
+	b.WriteString(stat)
+	for i, f := range files {
+		if !used[i] {
+			continue
+		}
+		b.WriteString("\n")
+		b.WriteString(strings.Join(f.Header, "\n"))
+		b.WriteString("\n")
+		for j, h := range f.Hunks {
+			if picked[i][j] {
+				b.WriteString(h.String())
+			}
+		}
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "\n... (%d hunks omitted)\n", omitted)
+	}
+
+	return b.String()
+}
+
+// EstimateTokens approximates the token count of s at four bytes per token.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// TruncateTokens cuts s to roughly tokens tokens without splitting a rune.
+func TruncateTokens(s string, tokens int) string {
+	limit := tokens * 4
+	if tokens <= 0 || len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit] + "\n... (truncated)"
+}
+
+// MatchAnyGlob reports whether name matches one of patterns. Patterns
+// without a slash match the base name, `**` matches across directories.
+func MatchAnyGlob(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					re.WriteString("(.*/)?")
+				} else {
+					re.WriteString(".*")
+				}
+			} else {
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+
+	ok, _ := regexp.MatchString(re.String(), name)
+	return ok
+}
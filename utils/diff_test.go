@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
++import "fmt"
+ func main() {
+-	println("hi")
+@@ -10,2 +11,3 @@ func helper() {
+ 	x := 1
++	y := 2
+diff --git a/go.sum b/go.sum
+index 3333333..4444444 100644
+--- a/go.sum
++++ b/go.sum
+@@ -1,1 +1,2 @@
+ example.com/a v1.0.0 h1:abc=
++example.com/b v1.0.0 h1:def=
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..5555555
+Binary files /dev/null and b/logo.png differ
+`
+
+func TestParseDiff(t *testing.T) {
+	files := ParseDiff(sampleDiff)
+
+	tests := []struct {
+		path    string
+		oldPath string
+		status  string
+		binary  bool
+		hunks   int
+		added   int
+		deleted int
+	}{
+		{"main.go", "main.go", "modified", false, 2, 2, 1},
+		{"go.sum", "go.sum", "modified", false, 1, 1, 0},
+		{"new.txt", "old.txt", "renamed", false, 0, 0, 0},
+		{"logo.png", "logo.png", "added", true, 0, 0, 0},
+	}
+	if len(files) != len(tests) {
+		t.Fatalf("got %d files, want %d", len(files), len(tests))
+	}
+	for i, tt := range tests {
+		f := files[i]
+		if f.Path != tt.path || f.OldPath != tt.oldPath || f.Status != tt.status || f.Binary != tt.binary {
+			t.Errorf("file %d = %s %s %s binary=%t, want %s %s %s binary=%t", i, f.Path, f.OldPath, f.Status, f.Binary, tt.path, tt.oldPath, tt.status, tt.binary)
+		}
+		if len(f.Hunks) != tt.hunks || f.Added != tt.added || f.Deleted != tt.deleted {
+			t.Errorf("%s: %d hunks +%d -%d, want %d hunks +%d -%d", f.Path, len(f.Hunks), f.Added, f.Deleted, tt.hunks, tt.added, tt.deleted)
+		}
+	}
+
+	if got := ParseDiff("not a diff"); len(got) != 0 {
+		t.Errorf("ParseDiff(not a diff) = %d files, want none", len(got))
+	}
+}
+
+func TestDiffStat(t *testing.T) {
+	files := ParseDiff(sampleDiff)
+	stat := DiffStat(files, func(f FileDiff) bool { return f.Path == "go.sum" })
+
+	for _, want := range []string{
+		" main.go | +2 -1\n",
+		" go.sum | +1 -0 (excluded)\n",
+		" old.txt => new.txt | +0 -0 [renamed]\n",
+		" logo.png | +0 -0 [added] (binary)\n",
+		" 4 files changed, 3 insertions(+), 1 deletions(-)\n",
+	} {
+		if !strings.Contains(stat, want) {
+			t.Errorf("stat is missing %q:\n%s", want, stat)
+		}
+	}
+}
+
+func TestCondenseDiff(t *testing.T) {
+	cfg := DiffConfig{Exclude: []string{"go.sum"}}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      []string
+		notWant   []string
+	}{
+		{
+			name:      "no budget keeps every included hunk",
+			maxTokens: 0,
+			want:      []string{"Files changed:", `+import "fmt"`, "+\ty := 2", "go.sum | +1 -0 (excluded)"},
+			notWant:   []string{"example.com/b", "Binary files", "omitted"},
+		},
+		{
+			name:      "large budget",
+			maxTokens: 10000,
+			want:      []string{`+import "fmt"`, "+\ty := 2"},
+			notWant:   []string{"example.com/b", "omitted"},
+		},
+		{
+			name:      "small budget omits hunks but keeps the stat",
+			maxTokens: 90,
+			want:      []string{"Files changed:", "main.go | +2 -1", "hunks omitted"},
+			notWant:   []string{"example.com/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.MaxTokens = tt.maxTokens
+			got := CondenseDiff(sampleDiff, cfg, "model")
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("missing %q in:\n%s", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("unexpected %q in:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchAnyGlob(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		name     string
+		want     bool
+	}{
+		{[]string{"*.lock"}, "Cargo.lock", true},
+		{[]string{"*.lock"}, "deep/dir/yarn.lock", true},
+		{[]string{"go.sum"}, "tools/go.sum", true},
+		{[]string{"*.min.js"}, "app.js", false},
+		{[]string{"vendor/**"}, "vendor/a/b.go", true},
+		{[]string{"vendor/**"}, "src/vendor/a.go", false},
+		{[]string{"**/testdata/*"}, "pkg/testdata/x.json", true},
+		{[]string{"**/testdata/*"}, "testdata/x.json", true},
+		{[]string{"**/testdata/*"}, "pkg/testdata/sub/x.json", false},
+		{[]string{"docs/?.md"}, "docs/a.md", true},
+		{[]string{"docs/?.md"}, "docs/ab.md", false},
+		{nil, "main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchAnyGlob(tt.patterns, tt.name); got != tt.want {
+			t.Errorf("MatchAnyGlob(%q, %q) = %t, want %t", tt.patterns, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Draft is an unfinished commit message, kept per branch so that an
+// aborted session or a rejected commit can be picked up again.
+type Draft struct {
+	Branch   string    `json:"branch"`
+	Gitmoji  string    `json:"gitmoji,omitempty"`
+	Type     string    `json:"type"`
+	Scope    string    `json:"scope"`
+	Breaking bool      `json:"breaking,omitempty"`
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+	Updated  time.Time `json:"updated"`
+}
+
+// Empty reports whether there is nothing worth resuming.
+func (d Draft) Empty() bool {
+	return strings.TrimSpace(d.Subject) == "" && strings.TrimSpace(d.Body) == ""
+}
+
+// Same compares the contents of two drafts, ignoring when they were saved.
+func (d Draft) Same(o Draft) bool {
+	d.Updated, o.Updated = time.Time{}, time.Time{}
+	return d == o
+}
+
+// MessageDraft splits an existing commit message into the parts of a
+// draft, for editing it again. A header that is not conventional ends
+// up in the subject as a whole.
+func MessageDraft(msg string) Draft {
+	header, body, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	d := Draft{Subject: header, Body: strings.TrimSpace(body)}
+	if h, ok := ParseHeader(header); ok && h.Autosquash == "" {
+		d.Gitmoji, d.Type, d.Scope, d.Breaking, d.Subject = h.Gitmoji, h.Type, h.Scope, h.Breaking, h.Subject
+	}
+	return d
+}
+
+// draftPath is .git/overcommit/drafts/<branch>.json, the branch name
+// escaped so that feature/x does not become a directory.
+func draftPath(branch string) (string, error) {
+	dir, err := GitDir()
+	if err != nil {
+		return "", err
+	}
+	if branch == "" {
+		branch = "HEAD"
+	}
+	return filepath.Join(dir, "overcommit", "drafts", url.PathEscape(branch)+".json"), nil
+}
+
+func LoadDraft(branch string) (Draft, bool) {
+	path, err := draftPath(branch)
+	if err != nil {
+		return Draft{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Draft{}, false
+	}
+
+	var d Draft
+	if err := json.Unmarshal(data, &d); err != nil || d.Empty() {
+		return Draft{}, false
+	}
+	return d, true
+}
+
+// SaveDraft writes d for its branch, replacing the previous draft. An
+// empty draft removes it instead.
+func SaveDraft(d Draft) error {
+	if d.Empty() {
+		return ClearDraft(d.Branch)
+	}
+
+	path, err := draftPath(d.Branch)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	d.Updated = time.Now()
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write and rename so a crash never leaves half a draft behind
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func ClearDraft(branch string) error {
+	path, err := draftPath(branch)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// GitError is a git command that failed, with its exit status and what
+// it printed to stderr.
+type GitError struct {
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *GitError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("git %s: %s", e.Args[0], e.Stderr)
+	}
+	return fmt.Sprintf("git %s: %v", e.Args[0], e.Err)
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode is the status a process should exit with after err: the exit
+// status of a failed command, 1 for anything else.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && gitErr.ExitCode > 0 {
+		return gitErr.ExitCode
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// Git runs a git command and returns its stdout. Failures come back as
+// *GitError.
+func Git(stdin io.Reader, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := runGitCmd(stdin, &stdout, nil, args)
+	return stdout.String(), err
+}
+
+// GitDir returns the absolute path of the repository's git directory. It
+// is resolved once, the first call spawns git.
+func GitDir() (string, error) {
+	return gitDir()
+}
+
+var gitDir = sync.OnceValues(func() (string, error) {
+	out, err := Git(nil, "rev-parse", "--absolute-git-dir")
+	return strings.TrimSpace(out), err
+})
+
+// GitPassthrough runs a git command with its output on the terminal, as
+// for git commit where hooks and editors talk to the user. stderr is
+// still captured into the error.
+func GitPassthrough(stdin io.Reader, args ...string) error {
+	return runGitCmd(stdin, os.Stdout, os.Stderr, args)
+}
+
+func runGitCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+
+	var captured bytes.Buffer
+	cmd.Stderr = &captured
+	if stderr != nil {
+		cmd.Stderr = io.MultiWriter(stderr, &captured)
+	}
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	gitErr := &GitError{Args: args, ExitCode: -1, Stderr: strings.TrimSpace(captured.String()), Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		gitErr.ExitCode = exitErr.ExitCode()
+	}
+	return gitErr
+}
+
+// CommitHooks lists the hooks that git commit runs in this repository,
+// honouring core.hooksPath.
+func CommitHooks() []string {
+	dir, err := Git(nil, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return nil
+	}
+	dir = strings.TrimSpace(dir)
+
+	var hooks []string
+	for _, name := range []string{"pre-commit", "prepare-commit-msg", "commit-msg"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.Mode()&0111 != 0 {
+			hooks = append(hooks, name)
+		}
+	}
+	return hooks
+}
+
+// SaveFailedMessage keeps the message of a failed commit in the git dir
+// and returns its path, for retrying with git commit -F.
+func SaveFailedMessage(msg string) (string, error) {
+	dir, err := GitDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "overcommit", "COMMIT_EDITMSG")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(msg+"\n"), 0644)
+}
@@ -1,9 +1,33 @@
 package utils
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
-func ExpandTemplate(str string, prefix string, region string, message string) string {
-	replacer := strings.NewReplacer("%p", prefix, "%r", region, "%m", message)
+// emptyField matches %t or %e with the brackets and one side of the
+// spaces around it, so that an empty ticket or emoji leaves no gap.
+func emptyField(field string) *regexp.Regexp {
+	f := `(?:\[` + field + `\]|\(` + field + `\)|` + field + `)`
+	return regexp.MustCompile(`^\s*` + f + `\s*|\s*` + f)
+}
+
+var (
+	emptyTicketRe = emptyField("%t")
+	emptyEmojiRe  = emptyField("%e")
+)
+
+// ExpandTemplate fills in prefix (%p), region (%r), message (%m), ticket
+// (%t) and emoji (%e). An empty ticket or emoji is dropped together with
+// its brackets and spacing, as in "[%t] %p: %m".
+func ExpandTemplate(str string, prefix string, region string, message string, ticket string, emoji string) string {
+	if ticket == "" {
+		str = emptyTicketRe.ReplaceAllString(str, "")
+	}
+	if emoji == "" {
+		str = emptyEmojiRe.ReplaceAllString(str, "")
+	}
+	replacer := strings.NewReplacer("%p", prefix, "%r", region, "%m", message, "%t", ticket, "%e", emoji)
 
 	return replacer.Replace(str)
 }
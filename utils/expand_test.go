@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		ticket   string
+		emoji    string
+		want     string
+	}{
+		{"plain", "%p(%r): %m", "", "", "feat(ui): x"},
+		{"spaces are kept", " %p(%r): %m ", "", "", " feat(ui): x "},
+		{"ticket in brackets", "[%t] %p(%r): %m", "ABC-1", "", "[ABC-1] feat(ui): x"},
+		{"empty ticket in brackets", "[%t] %p(%r): %m", "", "", "feat(ui): x"},
+		{"empty ticket in parens at the end", "%p(%r): %m (%t)", "", "", "feat(ui): x"},
+		{"empty bare ticket in the middle", "%p(%r): %t %m", "", "", "feat(ui): x"},
+		{"emoji", "%e %p(%r): %m", "", "✨", "✨ feat(ui): x"},
+		{"empty emoji", "%e %p(%r): %m", "", "", "feat(ui): x"},
+		{"empty emoji and ticket", "%e [%t] %p(%r): %m", "", "", "feat(ui): x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpandTemplate(tt.template, "feat", "ui", "x", tt.ticket, tt.emoji)
+			if got != tt.want {
+				t.Errorf("ExpandTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FixupKinds are the autosquash commits overcommit fixup can create.
+var FixupKinds = []string{"fixup", "squash", "amend"}
+
+// Commit is a commit as listed by git log.
+type Commit struct {
+	SHA     string
+	Subject string
+}
+
+func (c Commit) FilterValue() string {
+	return c.SHA + " " + c.Subject
+}
+
+// RecentCommits lists the last n commits on HEAD, newest first.
+func RecentCommits(n int) ([]Commit, error) {
+	out, err := Git(nil, "log", fmt.Sprintf("-%d", n), "--format=%H%x00%s")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if sha, subject, ok := strings.Cut(line, "\x00"); ok {
+			commits = append(commits, Commit{SHA: sha, Subject: subject})
+		}
+	}
+	return commits, nil
+}
+
+// FixupArgs is the git commit invocation creating a kind! commit for sha,
+// to be folded into it by git rebase --autosquash.
+func FixupArgs(kind, sha string) []string {
+	switch kind {
+	case "squash":
+		return []string{"commit", "--squash=" + sha}
+	case "amend":
+		// without staged changes an amend! commit only rewords its target
+		if !HasStagedChanges() {
+			return []string{"commit", "--fixup=reword:" + sha}
+		}
+		return []string{"commit", "--fixup=amend:" + sha}
+	}
+	return []string{"commit", "--fixup=" + sha}
+}
@@ -5,8 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
-	"regexp"
+	"path/filepath"
 	"strings"
 )
 
@@ -26,17 +25,34 @@ func BuildPrefixWithMsg(template Template, prefix string, msg string) string {
 	region, msg := extractRegionAndMsg(msg)
 
 	if region != "" {
-		return ExpandTemplate(template.Region, prefix, region, msg)
+		return ExpandTemplate(template.Region, prefix, region, msg, "", "")
 	}
 
-	return ExpandTemplate(template.Normal, prefix, region, msg)
+	return ExpandTemplate(template.Normal, prefix, region, msg, "", "")
 }
 
-func BuildCommitMessage(template Template, prefix string, scope string, msg string) string {
+// BuildCommitMessage expands the template for the header. A breaking
+// change gets its "!" in front of the colon following the type and scope.
+func BuildCommitMessage(template Template, prefix string, scope string, msg string, ticket string, emoji string, breaking bool) string {
+	str := template.Normal
 	if scope != "" {
-		return ExpandTemplate(template.Region, prefix, scope, msg)
+		str = template.Region
 	}
-	return ExpandTemplate(template.Normal, prefix, "", msg)
+	if breaking {
+		str = markBreaking(str)
+	}
+	return ExpandTemplate(str, prefix, scope, msg, ticket, emoji)
+}
+
+func markBreaking(template string) string {
+	i := strings.Index(template, "%p")
+	if i < 0 {
+		return template
+	}
+	if j := strings.Index(template[i:], ":"); j >= 0 {
+		return template[:i+j] + "!" + template[i+j:]
+	}
+	return template[:i+2] + "!" + template[i+2:]
 }
 
 func ReplaceHeaderFromCommit(text string, filename string) error {
@@ -59,10 +75,12 @@ func ReplaceHeaderFromCommit(text string, filename string) error {
 	// replace the first line with text
 	splitByEOL[0] = text
 
-	file.Truncate(0)
-	file.WriteAt([]byte(strings.Join(splitByEOL, "\n")), 0)
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err = file.WriteAt([]byte(strings.Join(splitByEOL, "\n")), 0)
 
-	return nil
+	return err
 }
 
 func GetCommitMsgFromFile(fileName string) (string, error) {
@@ -97,12 +115,11 @@ func GetScopes() []string {
 	}
 
 	// Get scopes from past commits
-	out, err := exec.Command("git", "log", "--oneline", "-100", "--format=%s").Output()
+	out, err := Git(nil, "log", "--oneline", "-100", "--format=%s")
 	if err == nil {
-		re := regexp.MustCompile(`^\w+\(([^)]+)\):`)
-		for _, line := range strings.Split(string(out), "\n") {
-			if matches := re.FindStringSubmatch(line); len(matches) > 1 {
-				addScope(matches[1])
+		for _, line := range strings.Split(out, "\n") {
+			if h, ok := ParseHeader(line); ok {
+				addScope(h.Scope)
 			}
 		}
 	}
@@ -121,24 +138,55 @@ func GetScopes() []string {
 	return scopes
 }
 
+func GetRecentSubjects(n int) []string {
+	out, err := Git(nil, "log", fmt.Sprintf("-%d", n), "--format=%s")
+	if err != nil {
+		return nil
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects
+}
+
+func GetCurrentBranch() string {
+	out, err := Git(nil, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+func GetRepoName() string {
+	out, err := Git(nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(strings.TrimSpace(out))
+}
+
 func GetStagedDiff() (string, error) {
-	out, _ := exec.Command("git", "diff", "--cached", "-p", "--no-color").Output()
+	out, _ := Git(nil, "diff", "--cached", "-p", "--no-color")
 	if len(out) > 0 {
-		return string(out), nil
+		return out, nil
 	}
 
-	files, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+	files, err := Git(nil, "diff", "--cached", "--name-only")
 	if err != nil {
 		return "", err
 	}
 
 	var result strings.Builder
-	for _, f := range strings.Split(strings.TrimSpace(string(files)), "\n") {
+	for _, f := range strings.Split(strings.TrimSpace(files), "\n") {
 		if f == "" {
 			continue
 		}
-		content, _ := exec.Command("git", "show", ":"+f).Output()
-		result.WriteString(fmt.Sprintf("=== %s ===\n%s\n", f, string(content)))
+		content, _ := Git(nil, "show", ":"+f)
+		result.WriteString(fmt.Sprintf("=== %s ===\n%s\n", f, content))
 	}
 	return result.String(), nil
 }
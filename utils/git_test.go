@@ -0,0 +1,51 @@
+package utils
+
+import "testing"
+
+func TestBuildCommitMessage(t *testing.T) {
+	tmpl := Template{Normal: "%e %p: %m", Region: "%e %p(%r): %m"}
+
+	tests := []struct {
+		name     string
+		template Template
+		prefix   string
+		scope    string
+		emoji    string
+		breaking bool
+		want     string
+	}{
+		{"plain", tmpl, "feat", "", "", false, "feat: x"},
+		{"scope", tmpl, "feat", "api", "", false, "feat(api): x"},
+		{"breaking", tmpl, "feat", "", "", true, "feat!: x"},
+		{"breaking with scope", tmpl, "feat", "api", "", true, "feat(api)!: x"},
+		{"breaking with emoji", tmpl, "feat", "api", "✨", true, "✨ feat(api)!: x"},
+		{"template without colon", Template{Normal: "%p %m"}, "feat", "", "", true, "feat! x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildCommitMessage(tt.template, tt.prefix, tt.scope, "x", "", tt.emoji, tt.breaking)
+			if got != tt.want {
+				t.Errorf("BuildCommitMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageDraft(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want Draft
+	}{
+		{"feat(api)!: drop v1\n\nbody", Draft{Type: "feat", Scope: "api", Breaking: true, Subject: "drop v1", Body: "body"}},
+		{"✨ feat: add x", Draft{Gitmoji: "✨", Type: "feat", Subject: "add x"}},
+		{"fixup! feat: add x", Draft{Subject: "fixup! feat: add x"}},
+		{"Update readme", Draft{Subject: "Update readme"}},
+	}
+
+	for _, tt := range tests {
+		if got := MessageDraft(tt.msg); got != tt.want {
+			t.Errorf("MessageDraft(%q) = %+v, want %+v", tt.msg, got, tt.want)
+		}
+	}
+}
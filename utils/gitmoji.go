@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GitmojiConfig adds gitmoji to the commit types. With Preset the
+// built-in emoji are given to the matching types, the missing types are
+// added and templates without %e get it in front.
+type GitmojiConfig struct {
+	Preset bool `json:"preset" toml:"preset"`
+	// Format writes %e as "unicode" or "shortcode".
+	Format string `json:"format" toml:"format"`
+	// Convert rewrites the gitmoji in the header to Format, whichever
+	// form was typed.
+	Convert bool `json:"convert" toml:"convert"`
+}
+
+var gitmojiKeys = []Key{
+	{Prefix: "feat", Description: "introduce new features", Emoji: "✨", Shortcode: "sparkles"},
+	{Prefix: "fix", Description: "fix a bug", Emoji: "🐛", Shortcode: "bug"},
+	{Prefix: "docs", Description: "add or update documentation", Emoji: "📝", Shortcode: "memo"},
+	{Prefix: "style", Description: "improve structure or format of the code", Emoji: "🎨", Shortcode: "art"},
+	{Prefix: "refactor", Description: "refactor code", Emoji: "♻️", Shortcode: "recycle"},
+	{Prefix: "perf", Description: "improve performance", Emoji: "⚡️", Shortcode: "zap"},
+	{Prefix: "test", Description: "add or update tests", Emoji: "✅", Shortcode: "white_check_mark"},
+	{Prefix: "build", Description: "add or update the build system", Emoji: "📦️", Shortcode: "package"},
+	{Prefix: "ci", Description: "add or update CI", Emoji: "👷", Shortcode: "construction_worker"},
+	{Prefix: "chore", Description: "regular maintenance", Emoji: "🔧", Shortcode: "wrench"},
+	{Prefix: "revert", Description: "revert changes", Emoji: "⏪️", Shortcode: "rewind"},
+	{Prefix: "security", Description: "fix security issues", Emoji: "🔒️", Shortcode: "lock"},
+}
+
+// gitmojiRe matches a :shortcode: or a unicode emoji with its variation
+// selectors and joiners.
+var gitmojiRe = regexp.MustCompile(`^(:[a-z0-9_+-]+:|\p{So}[\x{FE0F}\x{200D}\p{So}]*)`)
+
+// Gitmoji is the emoji of k written in format.
+func (k Key) Gitmoji(format string) string {
+	if k.Shortcode != "" && (format == "shortcode" || k.Emoji == "") {
+		return ":" + k.Shortcode + ":"
+	}
+	return k.Emoji
+}
+
+func applyGitmojiPreset(cfg *Config) {
+	for _, preset := range gitmojiKeys {
+		found := false
+		for i, k := range cfg.Keys {
+			if k.Prefix != preset.Prefix {
+				continue
+			}
+			found = true
+			if k.Emoji == "" && k.Shortcode == "" {
+				cfg.Keys[i].Emoji, cfg.Keys[i].Shortcode = preset.Emoji, preset.Shortcode
+			}
+		}
+		if !found {
+			cfg.Keys = append(cfg.Keys, preset)
+		}
+	}
+
+	for _, t := range []*string{&cfg.Template.Normal, &cfg.Template.Region} {
+		if !strings.Contains(*t, "%e") {
+			*t = "%e " + *t
+		}
+	}
+}
+
+// ConvertGitmoji writes the gitmoji at the start of header, after any
+// autosquash prefix, in format. Only the emoji of keys are known.
+func ConvertGitmoji(header string, keys []Key, format string) string {
+	autosquash := autosquashRe.FindString(header)
+	rest := header[len(autosquash):]
+
+	emoji := gitmojiRe.FindString(rest)
+	if emoji == "" {
+		return header
+	}
+	for _, k := range keys {
+		if emoji == k.Emoji || emoji == ":"+k.Shortcode+":" {
+			return autosquash + k.Gitmoji(format) + rest[len(emoji):]
+		}
+	}
+	return header
+}
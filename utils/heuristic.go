@@ -0,0 +1,275 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type Suggestion struct {
+	Type    string
+	Scope   string
+	Subject string
+}
+
+func (s Suggestion) Header() string {
+	return Header{Type: s.Type, Scope: s.Scope, Subject: s.Subject}.String()
+}
+
+var dependencyFiles = []string{
+	"go.mod", "go.sum", "package.json", "package-lock.json", "pnpm-lock.yaml", "yarn.lock",
+	"Cargo.toml", "*.lock", "requirements*.txt", "pyproject.toml",
+}
+
+var (
+	// definitions on added lines in Go, Python, JS/TS, Rust, Ruby and shell
+	addedDefRe = regexp.MustCompile(`^\+\s*(?:export\s+)?(?:pub\s+)?(?:async\s+)?(?:func|def|function|fn|class|type)\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`)
+	// the enclosing definition git prints after a hunk range
+	hunkDefRe = regexp.MustCompile(`^@@[^@]*@@.*?(?:func|def|function|fn|class|type)\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`)
+)
+
+// HeuristicClient suggests commit messages from the shape of the diff
+// without any model, so it works fully offline.
+type HeuristicClient struct{}
+
+func (c *HeuristicClient) Generate(prompt Prompt) (Response, error) {
+	data := prompt.Data
+	if len(data.Files) == 0 {
+		return Response{}, fmt.Errorf("heuristic: no changes to describe")
+	}
+
+	s := Suggest(data.Files, data.Types)
+	if data.Type != "" {
+		s.Type = data.Type
+	}
+	if data.Scope != "" {
+		s.Scope = data.Scope
+	}
+
+	text := s.Subject
+	switch data.Kind {
+	case "body":
+		text = describeFiles(data.Files)
+	case "reword":
+		text = s.Header()
+	}
+	return Response{Text: text, Backend: "heuristic"}, nil
+}
+
+// SuggestStaged runs Suggest on the currently staged changes, limited to
+// the types of keys.
+func SuggestStaged(keys []Key) (Suggestion, bool) {
+	diff, err := GetStagedDiff()
+	if err != nil || diff == "" {
+		return Suggestion{}, false
+	}
+	files := ParseDiff(diff)
+	if len(files) == 0 {
+		return Suggestion{}, false
+	}
+	types := make([]string, len(keys))
+	for i, k := range keys {
+		types[i] = k.Prefix
+	}
+	return Suggest(files, types), true
+}
+
+// Suggest proposes type, scope and subject for a set of changed files.
+// The type is one of types, or any type when types is empty, and stays
+// empty when none of the guesses is configured.
+func Suggest(files []FileDiff, types []string) Suggestion {
+	s := Suggestion{Type: suggestType(files, types), Scope: suggestScope(files)}
+
+	added, removed, renamed, defs := 0, 0, 0, addedDefs(files)
+	for _, f := range files {
+		switch f.Status {
+		case "added":
+			added++
+		case "deleted":
+			removed++
+		case "renamed":
+			renamed++
+		}
+	}
+
+	first := files[0]
+	target := subjectTarget(files)
+	switch {
+	case s.Type == "test":
+		s.Subject = "update tests for " + target
+		if added == len(files) {
+			s.Subject = "add tests for " + target
+		}
+	case s.Type == "docs":
+		s.Subject = "update " + target
+	case renamed == len(files) && len(files) == 1:
+		s.Subject = fmt.Sprintf("rename %s to %s", path.Base(first.OldPath), path.Base(first.Path))
+	case removed == len(files):
+		s.Subject = "remove " + target
+	case added == len(files):
+		s.Subject = "add " + target
+	case len(defs) > 0:
+		s.Subject = fmt.Sprintf("add %s to %s", joinNames(defs), target)
+	default:
+		if names := changedDefs(files); len(names) > 0 {
+			s.Subject = fmt.Sprintf("update %s in %s", joinNames(names), target)
+		} else {
+			s.Subject = "update " + target
+		}
+	}
+
+	return s
+}
+
+func fileKind(p string) string {
+	base := path.Base(p)
+	ext := path.Ext(base)
+	switch {
+	case strings.Contains(base, "_test.") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.HasPrefix(p, "test/") || strings.HasPrefix(p, "tests/") ||
+		strings.Contains(p, "/test/") || strings.Contains(p, "/tests/"):
+		return "test"
+	case ext == ".md" || ext == ".rst" || ext == ".adoc" || strings.HasPrefix(p, "docs/") ||
+		strings.HasPrefix(strings.ToUpper(base), "LICENSE") || strings.HasPrefix(strings.ToUpper(base), "README"):
+		return "docs"
+	case MatchAnyGlob(dependencyFiles, p):
+		return "deps"
+	case ext == ".css" || ext == ".scss" || ext == ".sass" || ext == ".less":
+		return "style"
+	}
+	return "code"
+}
+
+// suggestType picks the first of the guessed types that is configured.
+func suggestType(files []FileDiff, types []string) string {
+	for _, t := range guessTypes(files) {
+		if len(types) == 0 || slices.Contains(types, t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// guessTypes lists the likely types of files, best guess first. The kind
+// of file wins over what the code changes look like, chore comes last.
+func guessTypes(files []FileDiff) []string {
+	kinds := make(map[string]int)
+	for _, f := range files {
+		kinds[fileKind(f.Path)]++
+	}
+
+	var guesses []string
+	for _, kind := range []string{"test", "docs", "style"} {
+		if kinds[kind] == len(files) {
+			guesses = append(guesses, kind)
+		}
+	}
+	if kinds["deps"] == len(files) {
+		guesses = append(guesses, "chore", "build")
+	}
+
+	added, deleted, newCode := 0, 0, false
+	for _, f := range files {
+		added += f.Added
+		deleted += f.Deleted
+		if f.Status == "added" && fileKind(f.Path) == "code" {
+			newCode = true
+		}
+	}
+	switch {
+	case newCode || len(addedDefs(files)) > 0:
+		guesses = append(guesses, "feat")
+	case deleted >= added:
+		guesses = append(guesses, "refactor")
+	default:
+		guesses = append(guesses, "fix")
+	}
+	return append(guesses, "chore")
+}
+
+// suggestScope is the first directory shared by all files, or the
+// directory of a single changed file.
+func suggestScope(files []FileDiff) string {
+	scope := ""
+	for i, f := range files {
+		dir := strings.SplitN(f.Path, "/", 2)[0]
+		if !strings.Contains(f.Path, "/") {
+			dir = ""
+		}
+		if i == 0 {
+			scope = dir
+		} else if dir != scope {
+			return ""
+		}
+	}
+	return scope
+}
+
+func subjectTarget(files []FileDiff) string {
+	name := path.Base(files[0].Path)
+	if fileKind(files[0].Path) == "code" && len(files) == 1 {
+		name = strings.TrimSuffix(name, path.Ext(name))
+	}
+	if len(files) > 1 {
+		name = fmt.Sprintf("%s and %d more", name, len(files)-1)
+	}
+	return name
+}
+
+func addedDefs(files []FileDiff) []string {
+	var names []string
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			for _, line := range h.Lines {
+				if m := addedDefRe.FindStringSubmatch(line); m != nil {
+					names = append(names, m[1])
+				}
+			}
+		}
+	}
+	return names
+}
+
+func changedDefs(files []FileDiff) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			if m := hunkDefRe.FindStringSubmatch(h.Header); m != nil && !seen[m[1]] {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	}
+	return names
+}
+
+func joinNames(names []string) string {
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return fmt.Sprintf("%s, %s and %d more", names[0], names[1], len(names)-2)
+}
+
+// describeFiles lists what happened to every file as body bullets.
+func describeFiles(files []FileDiff) string {
+	var b strings.Builder
+	for _, f := range files {
+		verb := "update"
+		switch f.Status {
+		case "added":
+			verb = "add"
+		case "deleted":
+			verb = "remove"
+		case "renamed":
+			verb = "rename " + f.OldPath + " to"
+		}
+		fmt.Fprintf(&b, "- %s %s (+%d -%d)\n", verb, f.Path, f.Added, f.Deleted)
+	}
+	return b.String()
+}
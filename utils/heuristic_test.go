@@ -0,0 +1,58 @@
+package utils
+
+import "testing"
+
+func TestSuggestType(t *testing.T) {
+	all := []string{"feat", "fix", "docs", "style", "refactor", "test", "chore"}
+
+	tests := []struct {
+		name  string
+		files []FileDiff
+		types []string
+		want  string
+	}{
+		{"tests only", []FileDiff{{Path: "utils/diff_test.go", Added: 3}}, all, "test"},
+		{"docs only", []FileDiff{{Path: "README.md", Added: 1}, {Path: "docs/usage.md", Added: 1}}, all, "docs"},
+		{"styles only", []FileDiff{{Path: "web/app.css", Added: 1}}, all, "style"},
+		{"dependencies", []FileDiff{{Path: "go.mod", Added: 1}, {Path: "go.sum", Added: 2}}, all, "chore"},
+		{"dependencies without chore", []FileDiff{{Path: "go.mod", Added: 1}}, []string{"feat", "build"}, "build"},
+		{"new code file", []FileDiff{{Path: "utils/x.go", Status: "added", Added: 10}}, all, "feat"},
+		{"mostly deletions", []FileDiff{{Path: "utils/x.go", Added: 1, Deleted: 5}}, all, "refactor"},
+		{"small change", []FileDiff{{Path: "utils/x.go", Added: 2, Deleted: 1}}, all, "fix"},
+		{"tests without a test type", []FileDiff{{Path: "x_test.go", Added: 2, Deleted: 1}}, []string{"fix", "chore"}, "fix"},
+		{"falls back to chore", []FileDiff{{Path: "x.go", Added: 2, Deleted: 1}}, []string{"chore"}, "chore"},
+		{"nothing configured fits", []FileDiff{{Path: "x.go", Added: 2, Deleted: 1}}, []string{"wip"}, ""},
+		{"no types allows any", []FileDiff{{Path: "README.md"}}, nil, "docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestType(tt.files, tt.types); got != tt.want {
+				t.Errorf("suggestType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestScope(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"utils/diff.go"}, "utils"},
+		{[]string{"utils/diff.go", "utils/sub/x.go"}, "utils"},
+		{[]string{"utils/diff.go", "components/files.go"}, ""},
+		{[]string{"main.go"}, ""},
+		{[]string{"utils/diff.go", "main.go"}, ""},
+	}
+
+	for _, tt := range tests {
+		var files []FileDiff
+		for _, p := range tt.paths {
+			files = append(files, FileDiff{Path: p})
+		}
+		if got := suggestScope(files); got != tt.want {
+			t.Errorf("suggestScope(%q) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
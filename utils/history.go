@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// historyLimit is how many committed messages the history file keeps.
+const historyLimit = 1000
+
+type HistoryEntry struct {
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo"`
+	Type    string    `json:"type"`
+	Scope   string    `json:"scope"`
+	Subject string    `json:"subject"`
+}
+
+func HistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "overcommit", "history.jsonl")
+}
+
+func ReadHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// AppendHistory records a committed message, dropping the oldest entries
+// beyond historyLimit.
+func AppendHistory(path string, e HistoryEntry) error {
+	entries, err := ReadHistory(path)
+	if err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	entries = append(entries, e)
+	if len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SubjectHistory lists earlier subjects for type and scope, newest first:
+// those written with overcommit in this repository, then other
+// repositories, then the matching git log subjects.
+func SubjectHistory(typ, scope string) []string {
+	seen := make(map[string]bool)
+	var subjects []string
+	add := func(s string) {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			subjects = append(subjects, s)
+		}
+	}
+
+	entries, _ := ReadHistory(HistoryPath())
+	repo := GetRepoName()
+	for _, sameRepo := range []bool{true, false} {
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			if e.Type == typ && e.Scope == scope && (e.Repo == repo) == sameRepo {
+				add(e.Subject)
+			}
+		}
+	}
+
+	for _, line := range GetRecentSubjects(500) {
+		if h, ok := ParseHeader(line); ok && h.Type == typ && h.Scope == scope {
+			add(h.Subject)
+		}
+	}
+	return subjects
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Header struct {
+	// Autosquash holds the fixup!, squash! or amend! prefixes of a commit
+	// made for git rebase --autosquash, as in "fixup! fix(ui): ...", and
+	// Gitmoji the unicode or :shortcode: emoji in front of the type.
+	Autosquash string
+	Gitmoji    string
+	Type       string
+	Scope      string
+	Breaking   bool
+	Subject    string
+}
+
+var (
+	headerRe     = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: ?(.*)$`)
+	autosquashRe = regexp.MustCompile(`^(?:(?:fixup|squash|amend)! )+`)
+)
+
+// ParseHeader splits a conventional commit header into its parts. The
+// autosquash prefixes of fixup and squash commits and a gitmoji are
+// accepted in front.
+func ParseHeader(header string) (Header, bool) {
+	header = strings.TrimSpace(header)
+	autosquash := autosquashRe.FindString(header)
+	rest := header[len(autosquash):]
+	gitmoji := gitmojiRe.FindString(rest)
+	rest = strings.TrimLeft(rest[len(gitmoji):], " ")
+
+	m := headerRe.FindStringSubmatch(rest)
+	if m == nil {
+		return Header{}, false
+	}
+	return Header{Autosquash: autosquash, Gitmoji: gitmoji, Type: m[1], Scope: m[2], Breaking: m[3] == "!", Subject: m[4]}, true
+}
+
+func (h Header) String() string {
+	header := h.Autosquash
+	if h.Gitmoji != "" {
+		header += h.Gitmoji + " "
+	}
+	header += h.Type
+	if h.Scope != "" {
+		header += "(" + h.Scope + ")"
+	}
+	if h.Breaking {
+		header += "!"
+	}
+	return header + ": " + h.Subject
+}
+
+// LintTicket requires messages of the types in l.RequireTicket to
+// reference a ticket somewhere in subject, body or trailers.
+func LintTicket(typ, msg string, l Lint) []string {
+	if !slices.Contains(l.RequireTicket, typ) {
+		return nil
+	}
+	re, err := regexp.Compile(l.TicketPattern)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid ticket pattern %q", l.TicketPattern)}
+	}
+	if !re.MatchString(msg) {
+		return []string{typ + " commits must reference a ticket"}
+	}
+	return nil
+}
+
+// LintSubject returns the rule violations of a commit subject, which is
+// the message part of the header without type and scope.
+func LintSubject(subject string, l Lint) []string {
+	var violations []string
+
+	if strings.TrimSpace(subject) == "" {
+		return []string{"message required"}
+	}
+	if strings.Contains(subject, "\n") {
+		violations = append(violations, "must be a single line")
+	}
+	if l.MaxSubjectLength > 0 && len(subject) > l.MaxSubjectLength {
+		violations = append(violations, fmt.Sprintf("exceeds %d chars", l.MaxSubjectLength))
+	}
+	if !l.AllowTrailingPeriod && strings.HasSuffix(subject, ".") {
+		violations = append(violations, "must not end with a period")
+	}
+
+	first, _ := utf8.DecodeRuneInString(subject)
+	switch l.SubjectCase {
+	case "lower":
+		if unicode.IsUpper(first) {
+			violations = append(violations, "must start lowercase")
+		}
+	case "upper":
+		if unicode.IsLower(first) {
+			violations = append(violations, "must start uppercase")
+		}
+	}
+
+	return violations
+}
@@ -4,82 +4,138 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"strings"
 )
 
 type LLMClient interface {
-	Generate(prompt string) (string, error)
+	Generate(prompt Prompt) (Response, error)
+}
+
+type Response struct {
+	Text    string
+	Backend string
+	Model   string
+	Cached  bool
+	Usage   Usage
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
 func NewLLMClient(cfg LLMConfig) LLMClient {
-	switch cfg.Backend {
+	clients := []LLMClient{newBackendClient(cfg, cfg.Primary())}
+	for _, b := range cfg.Fallback {
+		clients = append(clients, newBackendClient(cfg, b))
+	}
+
+	var client LLMClient = NewChainClient(clients, cfg.Retry)
+	if !cfg.Usage.Disabled {
+		client = NewLedgerClient(client, cfg.Usage)
+	}
+	if !cfg.Cache.Disabled {
+		client = NewCachedClient(client, cfg)
+	}
+	return client
+}
+
+func newBackendClient(cfg LLMConfig, b LLMBackend) LLMClient {
+	client := http.DefaultClient
+	if cfg.Cassette != "" {
+		client = &http.Client{Transport: &ReplayTransport{Path: cfg.Cassette, Record: cfg.Record}}
+	}
+
+	switch b.Backend {
 	case "openai":
-		return &OpenAIClient{model: cfg.Model}
+		return &OpenAIClient{model: b.Model, baseURL: b.BaseURL, http: client}
 	case "anthropic":
-		return &AnthropicClient{model: cfg.Model}
+		return &AnthropicClient{model: b.Model, baseURL: b.BaseURL, http: client}
+	case "mock":
+		return &MockClient{fixtures: cfg.Fixtures}
+	case "heuristic":
+		return &HeuristicClient{}
 	default:
-		return &OllamaClient{model: cfg.Model}
+		return &OllamaClient{model: b.Model, baseURL: b.BaseURL, http: client}
 	}
 }
 
-func BuildPrompt(commitType, scope, diff string) string {
-	scopeInfo := ""
-	if scope != "" {
-		scopeInfo = fmt.Sprintf("Scope: %s\n", scope)
+func maxTokens(prompt Prompt) int {
+	if prompt.MaxTokens > 0 {
+		return prompt.MaxTokens
 	}
+	return 100
+}
 
-	if len(diff) > 4000 {
-		diff = diff[:4000] + "\n... (truncated)"
+func baseURLOr(url, fallback string) string {
+	if url == "" {
+		return fallback
 	}
-
-	return fmt.Sprintf(`Generate a concise commit message for this diff.
-Commit type: %s
-%sOnly output the message text, no quotes, no prefix.
-
-Diff:
-%s`, commitType, scopeInfo, diff)
+	return strings.TrimSuffix(url, "/")
 }
 
 // Ollama
 type OllamaClient struct {
-	model string
+	model   string
+	baseURL string
+	http    *http.Client
 }
 
-func (c *OllamaClient) Generate(prompt string) (string, error) {
+func (c *OllamaClient) Generate(prompt Prompt) (Response, error) {
 	body := map[string]any{
 		"model":  c.model,
-		"prompt": prompt,
+		"system": prompt.System,
+		"prompt": prompt.User,
 		"stream": false,
 	}
+	if prompt.MaxTokens > 0 {
+		body["options"] = map[string]any{"num_predict": prompt.MaxTokens}
+	}
 	jsonBody, _ := json.Marshal(body)
 
-	resp, err := http.Post("http://localhost:11434/api/generate", "application/json", bytes.NewReader(jsonBody))
+	url := baseURLOr(c.baseURL, "http://localhost:11434") + "/api/generate"
+	resp, err := c.http.Post(url, "application/json", bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("ollama unavailable: %w", err)
+		return Response{}, fmt.Errorf("ollama unavailable: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != 200 {
+		return Response{}, newAPIError("ollama", resp)
+	}
+
 	var result struct {
-		Response string `json:"response"`
+		Response        string `json:"response"`
+		PromptEvalCount int    `json:"prompt_eval_count"`
+		EvalCount       int    `json:"eval_count"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return Response{}, err
+	}
+	if strings.TrimSpace(result.Response) == "" {
+		return Response{}, fmt.Errorf("no response from ollama")
 	}
-	return strings.TrimSpace(result.Response), nil
+	return Response{
+		Text:    strings.TrimSpace(result.Response),
+		Backend: "ollama",
+		Model:   c.model,
+		Usage:   Usage{InputTokens: result.PromptEvalCount, OutputTokens: result.EvalCount},
+	}, nil
 }
 
 // OpenAI
 type OpenAIClient struct {
-	model string
+	model   string
+	baseURL string
+	http    *http.Client
 }
 
-func (c *OpenAIClient) Generate(prompt string) (string, error) {
+func (c *OpenAIClient) Generate(prompt Prompt) (Response, error) {
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
-		return "", fmt.Errorf("OPENAI_API_KEY not set")
+		return Response{}, fmt.Errorf("OPENAI_API_KEY not set")
 	}
 
 	model := c.model
@@ -87,28 +143,32 @@ func (c *OpenAIClient) Generate(prompt string) (string, error) {
 		model = "gpt-4o-mini"
 	}
 
+	messages := []map[string]string{}
+	if prompt.System != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": prompt.System})
+	}
+	messages = append(messages, map[string]string{"role": "user", "content": prompt.User})
+
 	body := map[string]any{
-		"model": model,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
-		"max_tokens": 100,
+		"model":      model,
+		"messages":   messages,
+		"max_tokens": maxTokens(prompt),
 	}
 	jsonBody, _ := json.Marshal(body)
 
-	req, _ := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(jsonBody))
+	url := baseURLOr(c.baseURL, "https://api.openai.com") + "/v1/chat/completions"
+	req, _ := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", err
+		return Response{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("openai error: %s", string(b))
+		return Response{}, newAPIError("openai", resp)
 	}
 
 	var result struct {
@@ -117,25 +177,36 @@ func (c *OpenAIClient) Generate(prompt string) (string, error) {
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return Response{}, err
 	}
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("no response from openai")
+		return Response{}, fmt.Errorf("no response from openai")
 	}
-	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+	return Response{
+		Text:    strings.TrimSpace(result.Choices[0].Message.Content),
+		Backend: "openai",
+		Model:   model,
+		Usage:   Usage{InputTokens: result.Usage.PromptTokens, OutputTokens: result.Usage.CompletionTokens},
+	}, nil
 }
 
 // Anthropic
 type AnthropicClient struct {
-	model string
+	model   string
+	baseURL string
+	http    *http.Client
 }
 
-func (c *AnthropicClient) Generate(prompt string) (string, error) {
+func (c *AnthropicClient) Generate(prompt Prompt) (Response, error) {
 	apiKey := os.Getenv("ANTHROPIC_API_KEY")
 	if apiKey == "" {
-		return "", fmt.Errorf("ANTHROPIC_API_KEY not set")
+		return Response{}, fmt.Errorf("ANTHROPIC_API_KEY not set")
 	}
 
 	model := c.model
@@ -145,39 +216,48 @@ func (c *AnthropicClient) Generate(prompt string) (string, error) {
 
 	body := map[string]any{
 		"model":      model,
-		"max_tokens": 100,
+		"max_tokens": maxTokens(prompt),
 		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
+			{"role": "user", "content": prompt.User},
 		},
 	}
+	if prompt.System != "" {
+		body["system"] = prompt.System
+	}
 	jsonBody, _ := json.Marshal(body)
 
-	req, _ := http.NewRequest("POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(jsonBody))
+	url := baseURLOr(c.baseURL, "https://api.anthropic.com") + "/v1/messages"
+	req, _ := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", err
+		return Response{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("anthropic error: %s", string(b))
+		return Response{}, newAPIError("anthropic", resp)
 	}
 
 	var result struct {
 		Content []struct {
 			Text string `json:"text"`
 		} `json:"content"`
+		Usage Usage `json:"usage"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+		return Response{}, err
 	}
 	if len(result.Content) == 0 {
-		return "", fmt.Errorf("no response from anthropic")
+		return Response{}, fmt.Errorf("no response from anthropic")
 	}
-	return strings.TrimSpace(result.Content[0].Text), nil
+	return Response{
+		Text:    strings.TrimSpace(result.Content[0].Text),
+		Backend: "anthropic",
+		Model:   model,
+		Usage:   result.Usage,
+	}, nil
 }
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestClient(backend, url string) LLMClient {
+	switch backend {
+	case "openai":
+		return &OpenAIClient{model: "test", baseURL: url, http: http.DefaultClient}
+	case "anthropic":
+		return &AnthropicClient{model: "test", baseURL: url, http: http.DefaultClient}
+	default:
+		return &OllamaClient{model: "test", baseURL: url, http: http.DefaultClient}
+	}
+}
+
+func TestClientGenerate(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test")
+	t.Setenv("ANTHROPIC_API_KEY", "test")
+
+	tests := []struct {
+		name    string
+		backend string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"ollama ok", "ollama", 200, `{"response": " add x \n"}`, "add x", ""},
+		{"ollama non-200", "ollama", 500, `{"error": "model not found"}`, "", "ollama error: {\"error\": \"model not found\"}"},
+		{"ollama empty", "ollama", 200, `{"response": ""}`, "", "no response from ollama"},
+		{"ollama bad json", "ollama", 200, `{"response":`, "", "unexpected EOF"},
+		{"openai ok", "openai", 200, `{"choices": [{"message": {"content": "add x"}}]}`, "add x", ""},
+		{"openai non-200", "openai", 401, `{"error": "bad key"}`, "", "openai error: {\"error\": \"bad key\"}"},
+		{"openai empty choices", "openai", 200, `{"choices": []}`, "", "no response from openai"},
+		{"openai bad json", "openai", 200, `not json`, "", "invalid character"},
+		{"anthropic ok", "anthropic", 200, `{"content": [{"text": "add x"}]}`, "add x", ""},
+		{"anthropic non-200", "anthropic", 529, `{"error": "overloaded"}`, "", "anthropic error: {\"error\": \"overloaded\"}"},
+		{"anthropic empty content", "anthropic", 200, `{"content": []}`, "", "no response from anthropic"},
+		{"anthropic bad json", "anthropic", 200, `{]`, "", "invalid character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			resp, err := newTestClient(tt.backend, srv.URL).Generate(Prompt{System: "s", User: "u"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Text != tt.want || resp.Backend != tt.backend {
+				t.Fatalf("got %q from %q, want %q from %q", resp.Text, resp.Backend, tt.want, tt.backend)
+			}
+		})
+	}
+}
+
+func TestClientMissingAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	for _, backend := range []string{"openai", "anthropic"} {
+		_, err := newTestClient(backend, "http://127.0.0.1:0").Generate(Prompt{User: "u"})
+		if err == nil || !strings.Contains(err.Error(), "API_KEY not set") {
+			t.Fatalf("%s: err = %v", backend, err)
+		}
+	}
+}
+
+func TestMockClient(t *testing.T) {
+	prompt := Prompt{System: "s", User: "u"}
+	fixtures := filepath.Join(t.TempDir(), "fixtures.json")
+	os.WriteFile(fixtures, []byte(`{"`+PromptHash(prompt)+`": "add fixture", "*": "fallback"}`), 0644)
+
+	c := &MockClient{fixtures: fixtures}
+	if got, _ := c.Generate(prompt); got.Text != "add fixture" {
+		t.Fatalf("got %q", got.Text)
+	}
+	if got, _ := c.Generate(Prompt{User: "other"}); got.Text != "fallback" {
+		t.Fatalf("got %q", got.Text)
+	}
+
+	a, _ := (&MockClient{}).Generate(prompt)
+	b, _ := (&MockClient{}).Generate(prompt)
+	if a != b {
+		t.Fatalf("mock without fixtures is not deterministic: %q != %q", a.Text, b.Text)
+	}
+}
+
+func TestReplayTransport(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test")
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"choices": [{"message": {"content": "add recorded"}}]}`))
+	}))
+
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	prompt := Prompt{User: "u"}
+
+	recorder := &OpenAIClient{baseURL: srv.URL, http: &http.Client{Transport: &ReplayTransport{Path: cassette, Record: true}}}
+	if got, err := recorder.Generate(prompt); err != nil || got.Text != "add recorded" {
+		t.Fatalf("record: got %q, %v", got.Text, err)
+	}
+	srv.Close()
+
+	data, _ := os.ReadFile(cassette)
+	if strings.Contains(string(data), "Bearer") {
+		t.Fatal("cassette leaked the authorization header")
+	}
+
+	player := &OpenAIClient{baseURL: srv.URL, http: &http.Client{Transport: &ReplayTransport{Path: cassette}}}
+	if got, err := player.Generate(prompt); err != nil || got.Text != "add recorded" {
+		t.Fatalf("replay: got %q, %v", got.Text, err)
+	}
+	if _, err := player.Generate(Prompt{User: "unrecorded"}); err == nil {
+		t.Fatal("expected error for unrecorded request")
+	}
+	if calls != 1 {
+		t.Fatalf("server called %d times, want 1", calls)
+	}
+}
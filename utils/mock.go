@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// MockClient answers from a JSON fixture file mapping prompt hashes to
+// responses. A "*" entry is used for prompts without their own fixture.
+type MockClient struct {
+	fixtures string
+}
+
+func PromptHash(prompt Prompt) string {
+	sum := sha256.Sum256([]byte(prompt.System + "\x00" + prompt.User))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *MockClient) Generate(prompt Prompt) (Response, error) {
+	hash := PromptHash(prompt)
+	if c.fixtures == "" {
+		return Response{Text: "mock response " + hash[:8], Backend: "mock"}, nil
+	}
+
+	data, err := os.ReadFile(c.fixtures)
+	if err != nil {
+		return Response{}, fmt.Errorf("mock fixtures: %w", err)
+	}
+
+	var responses map[string]string
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return Response{}, fmt.Errorf("mock fixtures: %w", err)
+	}
+
+	if text, ok := responses[hash]; ok {
+		return Response{Text: text, Backend: "mock"}, nil
+	}
+	if text, ok := responses["*"]; ok {
+		return Response{Text: text, Backend: "mock"}, nil
+	}
+	return Response{}, fmt.Errorf("mock: no fixture for prompt %s", hash)
+}
@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type PromptConfig struct {
+	System   string `json:"system" toml:"system"`
+	User     string `json:"user" toml:"user"`
+	Body     string `json:"body" toml:"body"`
+	Reword   string `json:"reword" toml:"reword"`
+	Examples int    `json:"examples" toml:"examples"`
+}
+
+type Prompt struct {
+	System string
+	User   string
+	// MaxTokens caps the answer length, zero leaves it to the client.
+	MaxTokens int
+	// Data is what the prompt was rendered from, for backends that work
+	// on the diff itself rather than on text.
+	Data PromptData
+}
+
+func (p Prompt) String() string {
+	if p.System == "" {
+		return p.User
+	}
+	return fmt.Sprintf("[system]\n%s\n\n[user]\n%s", p.System, p.User)
+}
+
+// PromptData is what the user prompt template is rendered with.
+type PromptData struct {
+	// Kind is "subject", "body" or "reword".
+	Kind          string
+	Type          string
+	Scope         string
+	Subject       string
+	Message       string
+	Types         []string
+	Diff          string
+	Files         []FileDiff
+	Branch        string
+	RecentCommits []string
+	Examples      []string
+}
+
+const defaultSystemPrompt = `You write git commit messages in the Conventional Commits style.`
+
+const defaultUserPrompt = `Generate a concise commit message for this diff.
+Commit type: {{.Type}}
+{{if .Scope}}Scope: {{.Scope}}
+{{end}}{{if .Examples}}
+Recent commits in this repository, match their voice:
+{{range .Examples}}- {{.}}
+{{end}}
+{{end}}Only output the message text, no quotes, no prefix.
+
+Diff:
+{{.Diff}}`
+
+const defaultBodyPrompt = `Write the body of a commit message for this diff. The subject line is already written:
+{{.Type}}{{if .Scope}}({{.Scope}}){{end}}: {{.Subject}}
+
+Explain why the change was made, then summarize the notable changes.
+Use short paragraphs or "- " bullet points in plain text.
+Do not repeat the subject line and do not use markdown headings or code fences.
+
+Diff:
+{{.Diff}}`
+
+const defaultRewordPrompt = `Rewrite this commit message as a Conventional Commits header.
+Allowed types: {{range $i, $t := .Types}}{{if $i}}, {{end}}{{$t}}{{end}}
+
+Current message:
+{{.Message}}
+
+Only output the header in the form type(scope): subject, the scope is optional.
+
+Diff:
+{{.Diff}}`
+
+func BuildPrompt(cfg PromptConfig, data PromptData) (Prompt, error) {
+	text := cfg.User
+	if text == "" {
+		text = defaultUserPrompt
+	}
+	data.Kind = "subject"
+	return renderPrompt(cfg, text, data)
+}
+
+func BuildBodyPrompt(cfg PromptConfig, data PromptData) (Prompt, error) {
+	text := cfg.Body
+	if text == "" {
+		text = defaultBodyPrompt
+	}
+	data.Kind = "body"
+
+	prompt, err := renderPrompt(cfg, text, data)
+	prompt.MaxTokens = 500
+	return prompt, err
+}
+
+func BuildRewordPrompt(cfg PromptConfig, data PromptData) (Prompt, error) {
+	text := cfg.Reword
+	if text == "" {
+		text = defaultRewordPrompt
+	}
+	data.Kind = "reword"
+	return renderPrompt(cfg, text, data)
+}
+
+func renderPrompt(cfg PromptConfig, text string, data PromptData) (Prompt, error) {
+	tmpl, err := template.New("prompt").Parse(text)
+	if err != nil {
+		return Prompt{}, fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return Prompt{}, fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	system := cfg.System
+	if system == "" {
+		system = defaultSystemPrompt
+	}
+
+	return Prompt{System: system, User: b.String(), Data: data}, nil
+}
+
+// PreparePrompt runs the staged diff through redaction and condensation and
+// renders the configured subject prompt for it. It also returns the
+// redaction count.
+func PreparePrompt(cfg LLMConfig, commitType, scope string) (Prompt, int, error) {
+	data, redacted, err := preparePromptData(cfg, commitType, scope)
+	if err != nil {
+		return Prompt{}, redacted, err
+	}
+
+	prompt, err := BuildPrompt(cfg.Prompt, data)
+	return prompt, redacted, err
+}
+
+// PrepareBodyPrompt is PreparePrompt for the commit body of subject.
+func PrepareBodyPrompt(cfg LLMConfig, commitType, scope, subject string) (Prompt, int, error) {
+	data, redacted, err := preparePromptData(cfg, commitType, scope)
+	if err != nil {
+		return Prompt{}, redacted, err
+	}
+	data.Subject = subject
+
+	prompt, err := BuildBodyPrompt(cfg.Prompt, data)
+	return prompt, redacted, err
+}
+
+// PrepareRewordPrompt renders the prompt asking for a conventional header
+// replacing the message of an existing commit.
+func PrepareRewordPrompt(cfg LLMConfig, keys []Key, sha string) (Prompt, int, error) {
+	diff, err := GetCommitDiff(sha)
+	if err != nil {
+		return Prompt{}, 0, err
+	}
+
+	data, redacted, err := promptDataForDiff(cfg, diff, "", "")
+	if err != nil {
+		return Prompt{}, redacted, err
+	}
+	data.Message = GetCommitMessage(sha)
+	for _, k := range keys {
+		data.Types = append(data.Types, k.Prefix)
+	}
+
+	prompt, err := BuildRewordPrompt(cfg.Prompt, data)
+	return prompt, redacted, err
+}
+
+func preparePromptData(cfg LLMConfig, commitType, scope string) (PromptData, int, error) {
+	diff, err := GetStagedDiff()
+	if err != nil {
+		return PromptData{}, 0, err
+	}
+	if diff == "" {
+		return PromptData{}, 0, fmt.Errorf("no staged changes")
+	}
+	return promptDataForDiff(cfg, diff, commitType, scope)
+}
+
+func promptDataForDiff(cfg LLMConfig, diff, commitType, scope string) (PromptData, int, error) {
+	var err error
+	redacted := 0
+	if cfg.ShouldRedact() {
+		diff, redacted, err = Redact(diff, cfg.Redact)
+		if err != nil {
+			return PromptData{}, redacted, err
+		}
+	}
+
+	recent := GetRecentSubjects(100)
+	data := PromptData{
+		Type:   commitType,
+		Scope:  scope,
+		Diff:   CondenseDiff(diff, cfg.Diff, cfg.Model),
+		Files:  ParseDiff(diff),
+		Branch: GetCurrentBranch(),
+	}
+	if len(recent) > 10 {
+		data.RecentCommits = recent[:10]
+	} else {
+		data.RecentCommits = recent
+	}
+	for _, s := range recent {
+		if len(data.Examples) >= cfg.Prompt.Examples {
+			break
+		}
+		// autosquash subjects only repeat the commit they fix
+		if h, ok := ParseHeader(s); ok && h.Autosquash == "" && h.Subject != "" {
+			data.Examples = append(data.Examples, s)
+		}
+	}
+
+	return data, redacted, nil
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+type RedactConfig struct {
+	// Policy is one of "redact", "block" or "off".
+	Policy    string   `json:"policy" toml:"policy"`
+	SkipLocal bool     `json:"skip_local" toml:"skip_local"`
+	Patterns  []string `json:"patterns" toml:"patterns"`
+	DenyPaths []string `json:"deny_paths" toml:"deny_paths"`
+	Entropy   float64  `json:"entropy" toml:"entropy"`
+}
+
+var defaultDenyPaths = []string{
+	".env", ".env.*", "*.pem", "*.key", "*.p12", "*.pfx", "id_rsa*", "id_ed25519*",
+	".npmrc", ".netrc", "credentials*",
+}
+
+// detector masks whole matches of re, or only the submatch named secret
+// when re has one so that the surrounding syntax stays intact.
+type detector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+func (d detector) replace(s string, mask func() string) string {
+	i := d.re.SubexpIndex("secret")
+	if i < 0 {
+		return d.re.ReplaceAllStringFunc(s, func(string) string { return mask() })
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range d.re.FindAllStringSubmatchIndex(s, -1) {
+		if m[2*i] < 0 {
+			continue
+		}
+		b.WriteString(s[last:m[2*i]])
+		b.WriteString(mask())
+		last = m[2*i+1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+var builtinDetectors = []detector{
+	{"private-key", regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws-access-key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-key", regexp.MustCompile(`(?i)aws.{0,20}(?:secret|key)\S{0,20}\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+]{40})\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{"github-token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"api-key", regexp.MustCompile(`\bsk-(ant-)?[A-Za-z0-9_-]{20,}`)},
+}
+
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+
+// ShouldRedact reports whether diffs must be redacted before reaching the
+// configured backend.
+func (c LLMConfig) ShouldRedact() bool {
+	if c.Redact.Policy == "off" {
+		return false
+	}
+	return !(c.Redact.SkipLocal && !c.IsRemote())
+}
+
+func IsRemoteBackend(backend string) bool {
+	return backend == "openai" || backend == "anthropic"
+}
+
+// Redact masks secrets in diff and returns the masked diff together with
+// the number of redactions. With the "block" policy any finding is an error.
+func Redact(diff string, cfg RedactConfig) (string, int, error) {
+	detectors := builtinDetectors
+	for _, p := range cfg.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		detectors = append(detectors, detector{"custom", re})
+	}
+
+	diff, count := redactPaths(diff, cfg.DenyPaths)
+
+	for _, d := range detectors {
+		diff = d.replace(diff, func() string {
+			count++
+			return "[REDACTED:" + d.name + "]"
+		})
+	}
+
+	if cfg.Entropy > 0 {
+		diff = entropyCandidate.ReplaceAllStringFunc(diff, func(s string) string {
+			if shannonEntropy(s) < cfg.Entropy {
+				return s
+			}
+			count++
+			return "[REDACTED:high-entropy]"
+		})
+	}
+
+	if count > 0 && cfg.Policy == "block" {
+		return "", count, fmt.Errorf("generation blocked: %d secrets found in staged diff", count)
+	}
+	return diff, count, nil
+}
+
+// redactPaths withholds the content of every file in diff matching one of
+// the deny patterns, keeping only its `diff --git` line.
+func redactPaths(diff string, deny []string) (string, int) {
+	if len(deny) == 0 {
+		return diff, 0
+	}
+
+	lines := strings.SplitAfter(diff, "\n")
+	var b strings.Builder
+	count := 0
+	skipping := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			skipping = false
+			if i := strings.Index(line, " b/"); i >= 0 && MatchAnyGlob(deny, strings.TrimSpace(line[i+3:])) {
+				skipping = true
+				count++
+				b.WriteString(line)
+				b.WriteString("[REDACTED: file content withheld]\n")
+				continue
+			}
+		}
+		if !skipping {
+			b.WriteString(line)
+		}
+	}
+	return b.String(), count
+}
+
+func shannonEntropy(s string) float64 {
+	freq := make(map[rune]float64)
+	for _, r := range s {
+		freq[r]++
+	}
+
+	var entropy float64
+	n := float64(len(s))
+	for _, f := range freq {
+		p := f / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Reword struct {
+	SHA     string
+	Old     string
+	New     string
+	Skipped bool
+}
+
+// ResolveCommits expands rev, a single revision or a range like A..B, into
+// commits ordered oldest first.
+func ResolveCommits(rev string) ([]string, error) {
+	args := []string{"rev-list", "--reverse", rev}
+	if !strings.Contains(rev, "..") {
+		args = []string{"rev-parse", "--verify", rev + "^{commit}"}
+	}
+
+	out, err := Git(nil, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %s", rev)
+	}
+
+	shas := strings.Fields(out)
+	if len(shas) == 0 {
+		return nil, fmt.Errorf("no commits in %s", rev)
+	}
+	for _, sha := range shas {
+		if gitRun("merge-base", "--is-ancestor", sha, "HEAD") != nil {
+			return nil, fmt.Errorf("%s is not an ancestor of HEAD", sha[:7])
+		}
+	}
+	return shas, nil
+}
+
+func GetCommitMessage(sha string) string {
+	out, _ := Git(nil, "log", "-1", "--format=%B", sha)
+	return strings.TrimSpace(out)
+}
+
+func GetCommitDiff(sha string) (string, error) {
+	return Git(nil, "show", "--format=", "--no-color", "-p", sha)
+}
+
+// ReplaceSubject swaps the first line of message for header, keeping the
+// body and trailers.
+func ReplaceSubject(message, header string) string {
+	if i := strings.Index(message, "\n"); i >= 0 {
+		return header + message[i:]
+	}
+	return header
+}
+
+// ApplyRewords rewrites the messages of the accepted rewords. HEAD alone is
+// amended, older commits are rewritten by an automated interactive rebase
+// whose todo list is edited by `overcommit reword --apply-todo`. Only
+// messages change, so neither runs the commit hooks. Rewords with an
+// empty new subject keep the old one.
+func ApplyRewords(rewords []Reword) error {
+	var accepted []Reword
+	for _, r := range rewords {
+		if !r.Skipped && strings.TrimSpace(r.New) != "" {
+			accepted = append(accepted, r)
+		}
+	}
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	head, err := Git(nil, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if len(accepted) == 1 && accepted[0].SHA == strings.TrimSpace(head) {
+		msg := ReplaceSubject(GetCommitMessage(accepted[0].SHA), accepted[0].New)
+		return GitPassthrough(strings.NewReader(msg), "commit", "--amend", "--only", "--no-verify", "-F", "-")
+	}
+
+	base := accepted[0].SHA + "^"
+	args := []string{"rebase", "-i", "--autostash", base}
+	if gitRun("rev-parse", "--verify", "--quiet", base) != nil {
+		args = []string{"rebase", "-i", "--autostash", "--root"}
+		base = ""
+	}
+
+	revRange := "HEAD"
+	if base != "" {
+		revRange = base + "..HEAD"
+	}
+	if merges, _ := Git(nil, "rev-list", "--merges", revRange); merges != "" {
+		return fmt.Errorf("cannot reword across merge commits")
+	}
+
+	gitDir, err := GitDir()
+	if err != nil {
+		return err
+	}
+	for _, state := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(gitDir, state)); err == nil {
+			return fmt.Errorf("a rebase is already in progress")
+		}
+	}
+
+	// the exec lines of the todo read the message files while the rebase
+	// runs, which may outlive this process when it stops on a conflict, so
+	// they live in the git dir and are only removed once it finished
+	dir := filepath.Join(gitDir, "overcommit", "reword")
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var mapping strings.Builder
+	for i, r := range accepted {
+		msgFile := filepath.Join(dir, fmt.Sprintf("%d.msg", i))
+		if err := os.WriteFile(msgFile, []byte(ReplaceSubject(GetCommitMessage(r.SHA), r.New)), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(&mapping, "%s %s\n", r.SHA, msgFile)
+	}
+	mapFile := filepath.Join(dir, "map")
+	if err := os.WriteFile(mapFile, []byte(mapping.String()), 0644); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// GIT_SEQUENCE_EDITOR would win over the sequence.editor given below
+	os.Unsetenv("GIT_SEQUENCE_EDITOR")
+	editor := fmt.Sprintf("%s reword --apply-todo %s", shellQuote(exe), shellQuote(mapFile))
+	if err := GitPassthrough(nil, append([]string{"-c", "sequence.editor=" + editor}, args...)...); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// shellQuote quotes s for the shell git runs editors and exec lines with.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ApplyRewordTodo inserts an amend after every pick of a mapped commit in
+// the rebase todo list at todoPath. The amends skip the commit hooks, old
+// commits that predate them would otherwise stop the rebase halfway.
+func ApplyRewordTodo(mapPath, todoPath string) error {
+	data, err := os.ReadFile(mapPath)
+	if err != nil {
+		return err
+	}
+
+	msgFiles := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if parts := strings.SplitN(line, " ", 2); len(parts) == 2 {
+			msgFiles[parts[0]] = parts[1]
+		}
+	}
+
+	todo, err := os.Open(todoPath)
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	scanner := bufio.NewScanner(todo)
+	for scanner.Scan() {
+		line := scanner.Text()
+		out.WriteString(line + "\n")
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || (fields[0] != "pick" && fields[0] != "p") {
+			continue
+		}
+		for sha, msgFile := range msgFiles {
+			if strings.HasPrefix(sha, fields[1]) {
+				fmt.Fprintf(&out, "exec git commit --amend --only --allow-empty --no-verify -F %s\n", shellQuote(msgFile))
+			}
+		}
+	}
+	todo.Close()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(todoPath, []byte(out.String()), 0644)
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyRewordTodo(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		todo    string
+		want    string
+	}{
+		{
+			name:    "amend after mapped picks",
+			mapping: "aaaaaaa1111 /tmp/0.msg\nccccccc3333 /tmp/1.msg\n",
+			todo:    "pick aaaaaaa first\npick bbbbbbb second\npick ccccccc third\n",
+			want: "pick aaaaaaa first\n" +
+				"exec git commit --amend --only --allow-empty --no-verify -F '/tmp/0.msg'\n" +
+				"pick bbbbbbb second\n" +
+				"pick ccccccc third\n" +
+				"exec git commit --amend --only --allow-empty --no-verify -F '/tmp/1.msg'\n",
+		},
+		{
+			name:    "short pick and quoted path",
+			mapping: "aaaaaaa1111 /tmp/it's/0.msg\n",
+			todo:    "p aaaaaaa first\n",
+			want:    "p aaaaaaa first\nexec git commit --amend --only --allow-empty --no-verify -F '/tmp/it'\\''s/0.msg'\n",
+		},
+		{
+			name:    "comments and other commands are kept",
+			mapping: "aaaaaaa1111 /tmp/0.msg\n",
+			todo:    "# Rebase onto x\nexec make\n\nreword aaaaaaa first\n",
+			want:    "# Rebase onto x\nexec make\n\nreword aaaaaaa first\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			mapPath, todoPath := filepath.Join(dir, "map"), filepath.Join(dir, "todo")
+			os.WriteFile(mapPath, []byte(tt.mapping), 0644)
+			os.WriteFile(todoPath, []byte(tt.todo), 0644)
+
+			if err := ApplyRewordTodo(mapPath, todoPath); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(todoPath)
+			if string(got) != tt.want {
+				t.Errorf("todo =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
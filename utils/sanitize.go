@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	labelRe    = regexp.MustCompile(`(?i)^(commit message|message|subject|body)\s*:\s*`)
+	markdownRe = regexp.MustCompile("^([#>*-]+\\s+|\\d+\\.\\s+)")
+	emphasisRe = regexp.MustCompile("\\*\\*|__|`")
+)
+
+// SanitizeSubject turns raw model output into a bare subject line: the first
+// line of text without markdown, quotes, labels, a repeated type/scope
+// prefix of one of l.Types or trailing punctuation.
+func SanitizeSubject(text string, l Lint) string {
+	subject := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		subject = line
+		break
+	}
+
+	for {
+		before := subject
+		subject = markdownRe.ReplaceAllString(subject, "")
+		subject = emphasisRe.ReplaceAllString(subject, "")
+		subject = labelRe.ReplaceAllString(subject, "")
+		subject = strings.Trim(subject, "\"'“”‘’ ")
+		if h, ok := ParseHeader(subject); ok && slices.Contains(l.Types, h.Type) {
+			subject = h.Subject
+		}
+		if subject == before {
+			break
+		}
+	}
+
+	cutset := ".!;:, "
+	if l.AllowTrailingPeriod {
+		cutset = ";:, "
+	}
+	subject = strings.TrimRight(subject, cutset)
+	if subject == "" {
+		return ""
+	}
+
+	first, size := utf8.DecodeRuneInString(subject)
+	second, _ := utf8.DecodeRuneInString(subject[size:])
+	switch l.SubjectCase {
+	case "lower":
+		if !unicode.IsUpper(second) {
+			subject = string(unicode.ToLower(first)) + subject[size:]
+		}
+	case "upper":
+		subject = string(unicode.ToUpper(first)) + subject[size:]
+	}
+
+	return subject
+}
+
+// GenerateSubject asks client for a subject and re-asks with the lint
+// violations as feedback until it passes or retries run out.
+func GenerateSubject(client LLMClient, prompt Prompt, l Lint, retries int) (Response, error) {
+	original := prompt.User
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Generate(prompt)
+		if err != nil {
+			return Response{}, err
+		}
+
+		resp.Text = SanitizeSubject(resp.Text, l)
+		violations := LintSubject(resp.Text, l)
+		if len(violations) == 0 {
+			return resp, nil
+		}
+		forget(client, prompt)
+		if attempt >= retries {
+			return resp, nil
+		}
+
+		prompt.User = fmt.Sprintf("%s\n\nYour previous answer %q was rejected: %s. Reply with a corrected message only.",
+			original, resp.Text, strings.Join(violations, "; "))
+	}
+}
+
+// SanitizeHeader is SanitizeSubject for a full conventional header.
+func SanitizeHeader(text string, l Lint) (string, bool) {
+	line := ""
+	for _, s := range strings.Split(text, "\n") {
+		s = strings.TrimSpace(s)
+		if s != "" && !strings.HasPrefix(s, "```") {
+			line = s
+			break
+		}
+	}
+
+	line = markdownRe.ReplaceAllString(line, "")
+	line = emphasisRe.ReplaceAllString(line, "")
+	line = labelRe.ReplaceAllString(line, "")
+	line = strings.Trim(line, "\"'“”‘’ ")
+
+	h, ok := ParseHeader(line)
+	if !ok {
+		return line, false
+	}
+	h.Subject = SanitizeSubject(h.Subject, l)
+	return h.String(), true
+}
+
+// GenerateHeader is GenerateSubject for a full conventional header.
+func GenerateHeader(client LLMClient, prompt Prompt, l Lint, retries int) (Response, error) {
+	original := prompt.User
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Generate(prompt)
+		if err != nil {
+			return Response{}, err
+		}
+
+		header, ok := SanitizeHeader(resp.Text, l)
+		resp.Text = header
+
+		var violations []string
+		if !ok {
+			violations = []string{"not in the form type(scope): subject"}
+		} else {
+			h, _ := ParseHeader(header)
+			violations = LintSubject(h.Subject, l)
+		}
+		if len(violations) == 0 {
+			return resp, nil
+		}
+		forget(client, prompt)
+		if attempt >= retries {
+			return resp, fmt.Errorf("invalid header %q: %s", header, strings.Join(violations, ", "))
+		}
+
+		prompt.User = fmt.Sprintf("%s\n\nYour previous answer %q was rejected: %s. Reply with a corrected header only.",
+			original, header, strings.Join(violations, "; "))
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestSanitizeSubject(t *testing.T) {
+	l := Lint{SubjectCase: "any", Types: []string{"feat", "fix"}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"configured type is stripped", "feat(api): add endpoint", "add endpoint"},
+		{"breaking prefix is stripped", "fix!: drop v1", "drop v1"},
+		{"unknown word is kept", "Note: see http://x.com", "Note: see http://x.com"},
+		{"label and markdown", "**Subject:** `fix: handle nil`.", "handle nil"},
+		{"quotes", `"add retries"`, "add retries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeSubject(tt.input, l); got != tt.want {
+				t.Errorf("SanitizeSubject(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import "strings"
+
+// StatusEntry is one path from git status. X and Y are the index and
+// worktree states, '.' meaning unchanged.
+type StatusEntry struct {
+	Path      string
+	OrigPath  string
+	X, Y      byte
+	Untracked bool
+}
+
+func (e StatusEntry) Staged() bool {
+	return !e.Untracked && e.X != '.'
+}
+
+func (e StatusEntry) Unstaged() bool {
+	return e.Untracked || e.Y != '.'
+}
+
+// GetStatus lists changed and untracked paths from git status --porcelain=v2.
+func GetStatus() ([]StatusEntry, error) {
+	out, err := Git(nil, "status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(out), nil
+}
+
+func parseStatus(out string) []StatusEntry {
+	var entries []StatusEntry
+	records := strings.Split(out, "\x00")
+	for i := 0; i < len(records); i++ {
+		r := records[i]
+		switch {
+		case strings.HasPrefix(r, "? "):
+			entries = append(entries, StatusEntry{Path: r[2:], X: '.', Y: '?', Untracked: true})
+		case strings.HasPrefix(r, "1 "):
+			// 1 XY sub mH mI mW hH hI path
+			if f := strings.SplitN(r, " ", 9); len(f) == 9 {
+				entries = append(entries, StatusEntry{Path: f[8], X: f[1][0], Y: f[1][1]})
+			}
+		case strings.HasPrefix(r, "2 "):
+			// 2 XY sub mH mI mW hH hI score path, followed by the original path
+			if f := strings.SplitN(r, " ", 10); len(f) == 10 {
+				e := StatusEntry{Path: f[9], X: f[1][0], Y: f[1][1]}
+				if i+1 < len(records) {
+					i++
+					e.OrigPath = records[i]
+				}
+				entries = append(entries, e)
+			}
+		case strings.HasPrefix(r, "u "):
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			if f := strings.SplitN(r, " ", 11); len(f) == 11 {
+				entries = append(entries, StatusEntry{Path: f[10], X: f[1][0], Y: f[1][1]})
+			}
+		}
+	}
+	return entries
+}
+
+// HasStagedChanges reports whether the index differs from HEAD.
+func HasStagedChanges() bool {
+	// --quiet exits with 1 when there are differences
+	_, err := Git(nil, "diff", "--cached", "--quiet")
+	return ExitCode(err) == 1
+}
+
+// GetFileDiff returns the staged or unstaged diff of a single entry.
+// Untracked files are diffed against /dev/null.
+func GetFileDiff(e StatusEntry, staged bool) (FileDiff, error) {
+	var out string
+	var err error
+	switch {
+	case staged:
+		out, err = Git(nil, "diff", "--cached", "--no-color", "-M", "--", e.Path)
+	case e.Untracked:
+		// --no-index exits with 1 when the files differ, which they always do
+		out, err = Git(nil, "diff", "--no-color", "--no-index", "--", "/dev/null", e.Path)
+		if ExitCode(err) == 1 {
+			err = nil
+		}
+	default:
+		out, err = Git(nil, "diff", "--no-color", "--", e.Path)
+	}
+	if err != nil {
+		return FileDiff{}, err
+	}
+
+	files := ParseDiff(out)
+	if len(files) == 0 {
+		return FileDiff{Path: e.Path}, nil
+	}
+	return files[0], nil
+}
+
+// StageFile adds the whole file to the index.
+func StageFile(path string) error {
+	return gitRun("add", "--", path)
+}
+
+// UnstageFile resets the file in the index to HEAD.
+func UnstageFile(e StatusEntry) error {
+	paths := []string{e.Path}
+	if e.OrigPath != "" {
+		paths = append(paths, e.OrigPath)
+	}
+	if gitRun("rev-parse", "--verify", "--quiet", "HEAD") != nil {
+		return gitRun(append([]string{"rm", "--cached", "--quiet", "--"}, paths...)...)
+	}
+	return gitRun(append([]string{"reset", "--quiet", "--"}, paths...)...)
+}
+
+// StageHunk applies a single hunk of f to the index, or removes it from
+// the index when unstage is set.
+func StageHunk(f FileDiff, h Hunk, unstage bool) error {
+	patch := strings.Join(f.Header, "\n") + "\n" + h.String()
+	args := []string{"apply", "--cached", "--recount"}
+	if unstage {
+		args = append(args, "--reverse")
+	}
+	_, err := Git(strings.NewReader(patch), append(args, "-")...)
+	return err
+}
+
+func gitRun(args ...string) error {
+	_, err := Git(nil, args...)
+	return err
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var trailerRe = regexp.MustCompile(`^[A-Za-z0-9-]+: `)
+
+var defaultTrailerKeys = []string{"Co-authored-by", "Refs", "Reviewed-by", "Signed-off-by"}
+
+// TrailersConfig lists the trailer keys offered on the trailers page and
+// a team roster of "Name <email>" identities to complete people from.
+type TrailersConfig struct {
+	Keys   []string `json:"keys" toml:"keys"`
+	Roster []string `json:"roster" toml:"roster"`
+}
+
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// PersonTrailer reports whether the value of key names a person, as for
+// Co-authored-by or Reviewed-by.
+func PersonTrailer(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), "-by")
+}
+
+// People lists identities for person trailers: the roster first, then
+// everyone who committed to the repository, most commits first and with
+// .mailmap applied.
+func People(roster []string) []string {
+	seen := make(map[string]bool)
+	var people []string
+	add := func(p string) {
+		p = strings.TrimSpace(p)
+		if p != "" && !seen[p] {
+			seen[p] = true
+			people = append(people, p)
+		}
+	}
+
+	for _, p := range roster {
+		add(p)
+	}
+	out, err := Git(nil, "shortlog", "-sne", "HEAD")
+	if err != nil {
+		return people
+	}
+	for _, line := range strings.Split(out, "\n") {
+		// "   42\tName <email>"
+		if _, ident, ok := strings.Cut(line, "\t"); ok {
+			add(ident)
+		}
+	}
+	return people
+}
+
+// AddTrailers appends trailers to msg with git interpret-trailers, which
+// joins a trailer block already at the end of the message and skips
+// exact duplicates.
+func AddTrailers(msg string, trailers []Trailer) (string, error) {
+	if len(trailers) == 0 {
+		return msg, nil
+	}
+
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t.String())
+	}
+	out, err := Git(strings.NewReader(msg+"\n"), args...)
+	if err != nil {
+		return msg, err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// FormatTrailers appends trailers to msg the way AddTrailers does in the
+// common case, without running git, for previews.
+func FormatTrailers(msg string, trailers []Trailer) string {
+	paragraphs := strings.Split(msg, "\n\n")
+	last := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+
+	var block []string
+	if len(paragraphs) > 1 && isTrailerBlock(last) {
+		block = last
+		msg = strings.Join(paragraphs[:len(paragraphs)-1], "\n\n")
+	}
+	for _, t := range trailers {
+		if !slices.Contains(block, t.String()) {
+			block = append(block, t.String())
+		}
+	}
+	if len(block) == 0 {
+		return msg
+	}
+	return msg + "\n\n" + strings.Join(block, "\n")
+}
+
+func isTrailerBlock(lines []string) bool {
+	for _, line := range lines {
+		if !trailerRe.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type UsageConfig struct {
+	Disabled bool   `json:"disabled" toml:"disabled"`
+	Ledger   string `json:"ledger" toml:"ledger"`
+	// Prices are in USD per million tokens, keyed by model.
+	Prices map[string]Price `json:"prices" toml:"prices"`
+}
+
+type Price struct {
+	Input  float64 `json:"input" toml:"input"`
+	Output float64 `json:"output" toml:"output"`
+}
+
+var defaultPrices = map[string]Price{
+	"gpt-4o-mini":               {Input: 0.15, Output: 0.60},
+	"gpt-4o":                    {Input: 2.50, Output: 10.00},
+	"claude-3-haiku-20240307":   {Input: 0.25, Output: 1.25},
+	"claude-3-5-haiku-20241022": {Input: 0.80, Output: 4.00},
+}
+
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*p.Input + float64(u.OutputTokens)*p.Output) / 1e6
+}
+
+type UsageRecord struct {
+	Time    time.Time `json:"time"`
+	Repo    string    `json:"repo"`
+	Backend string    `json:"backend"`
+	Model   string    `json:"model"`
+	Usage
+	Cost float64 `json:"cost"`
+}
+
+// LedgerPath is where usage records are appended, one JSON object per line.
+func (c UsageConfig) LedgerPath() string {
+	if c.Ledger != "" {
+		return c.Ledger
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "overcommit", "usage.jsonl")
+}
+
+// LedgerClient records the token usage of every answer from inner.
+type LedgerClient struct {
+	inner LLMClient
+	cfg   UsageConfig
+	repo  string
+}
+
+func NewLedgerClient(inner LLMClient, cfg UsageConfig) LLMClient {
+	return &LedgerClient{inner: inner, cfg: cfg, repo: GetRepoName()}
+}
+
+func (c *LedgerClient) Generate(prompt Prompt) (Response, error) {
+	resp, err := c.inner.Generate(prompt)
+	if err != nil || resp.Cached || resp.Usage == (Usage{}) {
+		return resp, err
+	}
+
+	_ = AppendUsage(c.cfg.LedgerPath(), UsageRecord{
+		Time:    time.Now(),
+		Repo:    c.repo,
+		Backend: resp.Backend,
+		Model:   resp.Model,
+		Usage:   resp.Usage,
+		Cost:    c.cfg.Prices[resp.Model].Cost(resp.Usage),
+	})
+	return resp, nil
+}
+
+func AppendUsage(path string, r UsageRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(r)
+}
+
+func ReadUsage(path string) ([]UsageRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []UsageRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r UsageRecord
+		if json.Unmarshal(scanner.Bytes(), &r) == nil {
+			records = append(records, r)
+		}
+	}
+	return records, scanner.Err()
+}
+
+type UsageTotal struct {
+	Key   string
+	Calls int
+	Usage
+	Cost float64
+}
+
+// SummarizeUsage totals records grouped by "day", "model" or "repo".
+func SummarizeUsage(records []UsageRecord, by string) []UsageTotal {
+	totals := make(map[string]*UsageTotal)
+	for _, r := range records {
+		key := r.Time.Local().Format("2006-01-02")
+		switch by {
+		case "model":
+			key = r.Backend + "/" + r.Model
+		case "repo":
+			key = r.Repo
+		}
+
+		t, ok := totals[key]
+		if !ok {
+			t = &UsageTotal{Key: key}
+			totals[key] = t
+		}
+		t.Calls++
+		t.InputTokens += r.InputTokens
+		t.OutputTokens += r.OutputTokens
+		t.Cost += r.Cost
+	}
+
+	result := make([]UsageTotal, 0, len(totals))
+	for _, t := range totals {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}